@@ -1,101 +1,398 @@
 package api
 
 import (
+	"context"
+
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/config"
 	"go-fiber-api/internal/features/auth"
 	"go-fiber-api/internal/features/follow"
 	"go-fiber-api/internal/features/inventory"
 	"go-fiber-api/internal/features/merchant"
+	"go-fiber-api/internal/features/privacy"
 	"go-fiber-api/internal/features/products"
 	"go-fiber-api/internal/features/transactions"
 
 	// "go-fiber-api/internal/features/products"
 	"go-fiber-api/internal/middleware"
+	"go-fiber-api/internal/util/mailer"
+	"go-fiber-api/internal/util/payment"
+	"go-fiber-api/internal/util/permission"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/upload"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-func RegisterAuthRoutes(app *fiber.App, db *gorm.DB) {
+func newAuthRequired(db *gorm.DB, tokens *token.Tokens) fiber.Handler {
+	sessionRepo := auth.NewSessionRepository(db)
+	userRepo := auth.NewUserRepository(db)
+	sessionService := auth.NewSessionService(db, sessionRepo, userRepo)
+
+	return middleware.AuthRequired(tokens, sessionService)
+}
+
+// newAuthRequiredWithAPIKey dipakai untuk route yang boleh diakses
+// integrasi merchant (POS, ERP) dengan API key
+func newAuthRequiredWithAPIKey(db *gorm.DB, tokens *token.Tokens) fiber.Handler {
+	sessionRepo := auth.NewSessionRepository(db)
+	userRepo := auth.NewUserRepository(db)
+	sessionService := auth.NewSessionService(db, sessionRepo, userRepo)
+	apiKeyService := merchant.NewMerchantAPIKeyService(merchant.NewMerchantAPIKeyRepository(db), merchant.NewMerchantMemberRepository(db))
+
+	return middleware.AuthRequiredWithAPIKey(tokens, sessionService, merchant.NewAPIKeyValidatorAdapter(apiKeyService))
+}
+
+// newVerifiedEmailRequired hanya aktif kalau REQUIRE_VERIFIED_EMAIL=true
+func newVerifiedEmailRequired(db *gorm.DB, cfg *config.Config, tokens *token.Tokens) fiber.Handler {
+	verificationService := auth.NewEmailVerificationService(auth.NewUserRepository(db), mailer.New(cfg), tokens.Keys, cfg.AppBaseURL)
+
+	return middleware.RequireVerifiedEmail(verificationService, cfg.RequireVerifiedEmail)
+}
+
+// RegisterCSRFProtection harus dipanggil sebelum route lain didaftarkan.
+// Webhook payment gateway dikecualikan karena dipanggil server-to-server,
+// path-nya mengikuti gateway yang dipakai (lihat RegisterTransactionRoutes)
+func RegisterCSRFProtection(app *fiber.App, tokens *token.Tokens, gateway payment.PaymentGateway) {
+	app.Use(middleware.CSRFProtection(
+		tokens.Cookies,
+		"/api/transactions/webhook/"+gateway.Name(),
+	))
+}
+
+func RegisterAuthRoutes(app *fiber.App, db *gorm.DB, cfg *config.Config, tokens *token.Tokens) {
 	api := app.Group("/api/auth")
 	userRepository := auth.NewUserRepository(db)
+	sessionRepository := auth.NewSessionRepository(db)
 	authService := auth.NewAuthService(userRepository)
-	authHandler := auth.NewHandler(authService)
+	passwordResetRepository := auth.NewPasswordResetRepository(db)
+	sessionService := auth.NewSessionService(db, sessionRepository, userRepository)
+	passwordResetService := auth.NewPasswordResetService(
+		db,
+		userRepository,
+		passwordResetRepository,
+		sessionRepository,
+		mailer.New(cfg),
+		cfg.AppBaseURL,
+	)
+	verificationService := auth.NewEmailVerificationService(userRepository, mailer.New(cfg), tokens.Keys, cfg.AppBaseURL)
+	twoFactorService := auth.NewTwoFactorService(db, userRepository, auth.NewTwoFactorRepository(db))
+	loginThrottleService := auth.NewLoginThrottleService(auth.NewLoginThrottleRepository(db), userRepository)
+	authHandler := auth.NewHandler(
+		authService,
+		sessionService,
+		passwordResetService,
+		verificationService,
+		twoFactorService,
+		loginThrottleService,
+		tokens,
+	)
+	authRequired := middleware.AuthRequired(tokens, sessionService)
+	oidcService := auth.NewOIDCService(db, userRepository, auth.NewOIDCRepository(db), cfg.OIDCProviders, cfg.APIBaseURL)
+	oidcHandler := auth.NewOIDCHandler(oidcService, sessionService, twoFactorService, tokens, cfg.AppBaseURL)
+	profileService := auth.NewProfileService(db, userRepository, sessionRepository, mailer.New(cfg), tokens.Keys, cfg.AppBaseURL)
+	profileHandler := auth.NewProfileHandler(profileService, upload.NewSupabaseStorage(cfg.SupabaseURL, cfg.SupabaseServiceKey))
 
+	api.Get("/csrf", func(c *fiber.Ctx) error {
+		return response.Success(c, "csrf token", fiber.Map{"csrf_token": middleware.CSRFToken(c)})
+	})
 	api.Post("/logout", authHandler.LogoutUser)
 	api.Post("/register", authHandler.RegisterUser)
 	api.Post("/login", authHandler.LoginUser)
+	api.Post("/login/2fa", authHandler.LoginTwoFactor)
+	api.Get("/oidc/:provider/login", oidcHandler.Login)
+	api.Get("/oidc/:provider/callback", oidcHandler.Callback)
+	api.Post("/refresh", authHandler.RefreshToken)
+	api.Post("/password/forgot", authHandler.ForgotPassword)
+	api.Post("/password/reset", authHandler.ResetPassword)
+	api.Post("/email/verify", authHandler.VerifyEmail)
+	api.Post("/email/resend", authRequired, authHandler.ResendVerification)
 
-	api.Get("/user", middleware.AuthRequired, authHandler.GetUser)
+	api.Get("/user", authRequired, authHandler.GetUser)
+	api.Get("/me", authRequired, profileHandler.GetProfile)
+	api.Patch("/me", authRequired, profileHandler.UpdateProfile)
+	api.Post("/me/avatar", authRequired, profileHandler.UploadAvatar)
+	api.Post("/me/email", authRequired, profileHandler.ChangeEmail)
+	api.Post("/me/password", authRequired, profileHandler.ChangePassword)
+	api.Get("/sessions", authRequired, authHandler.GetSessions)
+	api.Delete("/sessions/others", authRequired, authHandler.RevokeOtherSessions)
+	api.Delete("/sessions/:id", authRequired, authHandler.RevokeSession)
+
+	api.Post("/2fa/setup", authRequired, authHandler.SetupTwoFactor)
+	api.Post("/2fa/enable", authRequired, authHandler.EnableTwoFactor)
+	api.Post("/2fa/disable", authRequired, authHandler.DisableTwoFactor)
+	api.Post("/2fa/recovery-codes", authRequired, authHandler.RegenerateRecoveryCodes)
 }
 
-func RegisterMerchantRoutes(app *fiber.App, db *gorm.DB) {
+func RegisterMerchantRoutes(app *fiber.App, db *gorm.DB, cfg *config.Config, tokens *token.Tokens) {
 	api := app.Group("/api/merchant")
+	authRequired := newAuthRequired(db, tokens)
 	merchantRepo := merchant.NewMerchantRepository(db)
+	memberRepo := merchant.NewMerchantMemberRepository(db)
 	merchantService := merchant.NewMerchantService(merchantRepo)
-	merchantHandler := merchant.NewMerchantHandler(merchantService)
+	merchantHandler := merchant.NewMerchantHandler(merchantService, upload.NewSupabaseStorage(cfg.SupabaseURL, cfg.SupabaseServiceKey))
+	userAdapter := auth.NewUserServiceAdapter(auth.NewAuthService(auth.NewUserRepository(db)))
+	memberService := merchant.NewMerchantMemberService(memberRepo, userAdapter, mailer.New(cfg), cfg.AppBaseURL)
+	memberHandler := merchant.NewMerchantMemberHandler(memberService)
+	apiKeyService := merchant.NewMerchantAPIKeyService(merchant.NewMerchantAPIKeyRepository(db), memberRepo)
+	apiKeyHandler := merchant.NewMerchantAPIKeyHandler(apiKeyService)
 
-	api.Post("/create", middleware.AuthRequired, merchantHandler.AddMerchant)
+	api.Post(
+		"/create",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantCreate),
+		newVerifiedEmailRequired(db, cfg, tokens),
+		merchantHandler.AddMerchant,
+	)
 
 	api.Get("/all", merchantHandler.GetAllMerchant)
-	api.Get("/my-summary", middleware.AuthRequired, merchantHandler.GetMyMerchantsSummary)
-	api.Get("/my-merchant/:id", middleware.AuthRequired, merchantHandler.GetMyMerchantDashboard)
+	api.Get("/my-summary", authRequired, merchantHandler.GetMyMerchantsSummary)
+	api.Get(
+		"/my-merchant/:id",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantDashboardRead, merchantFromParam(merchantRepo, memberRepo, "id")),
+		merchantHandler.GetMyMerchantDashboard,
+	)
 	api.Get("/display", merchantHandler.GetMerchantDisplay)
+
+	api.Post("/invitations/accept", authRequired, memberHandler.AcceptInvitation)
+	api.Get(
+		"/:id/members",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantMembersRead, merchantFromParam(merchantRepo, memberRepo, "id")),
+		memberHandler.GetMembers,
+	)
+	api.Post(
+		"/:id/members/invite",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantMembersManage, merchantFromParam(merchantRepo, memberRepo, "id")),
+		memberHandler.InviteMember,
+	)
+	api.Delete(
+		"/:id/members/:member_id",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantMembersManage, merchantFromParam(merchantRepo, memberRepo, "id")),
+		memberHandler.RemoveMember,
+	)
+
+	canManageAPIKeys := middleware.RequirePermission(permission.MerchantAPIKeysManage, merchantFromParam(merchantRepo, memberRepo, "id"))
+	api.Get("/:id/api-keys", authRequired, canManageAPIKeys, apiKeyHandler.GetAPIKeys)
+	api.Post("/:id/api-keys", authRequired, canManageAPIKeys, apiKeyHandler.CreateAPIKey)
+	api.Delete("/:id/api-keys/:key_id", authRequired, canManageAPIKeys, apiKeyHandler.RevokeAPIKey)
+
 	api.Get("/:id", merchantHandler.GetMerchantById)
 }
 
-func RegisterProductRoutes(app *fiber.App, db *gorm.DB) {
+func RegisterProductRoutes(app *fiber.App, db *gorm.DB, cfg *config.Config, tokens *token.Tokens) {
 	api := app.Group("/api/products")
+	authRequired := newAuthRequiredWithAPIKey(db, tokens)
 
 	productRepo := products.NewProductRepository(db)
 	merchantRepo := merchant.NewMerchantRepository(db)
+	memberRepo := merchant.NewMerchantMemberRepository(db)
 	merchantService := merchant.NewMerchantService(merchantRepo)
 	merchantAdapter := merchant.NewMerchantServiceAdapter(merchantService)
 
 	productService := products.NewProductService(productRepo, merchantAdapter)
-	productHandler := products.NewProductHandler(productService, merchantAdapter)
+	productHandler := products.NewProductHandler(productService, merchantAdapter, upload.NewSupabaseStorage(cfg.SupabaseURL, cfg.SupabaseServiceKey))
 
-	api.Get("/dashboard/:merchant_id", middleware.AuthRequired, productHandler.GetMerchantProductsDashboard)
+	api.Get(
+		"/dashboard/:merchant_id",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantProductsRead, merchantFromParam(merchantRepo, memberRepo, "merchant_id")),
+		productHandler.GetMerchantProductsDashboard,
+	)
 	api.Get("/merchant/:id", productHandler.GetMerchantProducts)
-	api.Post("/bulk-delete", middleware.AuthRequired, productHandler.BulkDeleteMerchantProducts)
-	api.Post("/add/:merchant_id", middleware.AuthRequired, productHandler.CreateProduct)
+	api.Post(
+		"/bulk-delete",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantProductsWrite, ownMerchant(merchantRepo, memberRepo)),
+		productHandler.BulkDeleteMerchantProducts,
+	)
+	api.Post(
+		"/bulk-delete/:merchant_id",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantProductsWrite, merchantFromParam(merchantRepo, memberRepo, "merchant_id")),
+		productHandler.BulkDeleteMerchantProducts,
+	)
+	api.Post(
+		"/add/:merchant_id",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantProductsWrite, merchantFromParam(merchantRepo, memberRepo, "merchant_id")),
+		productHandler.CreateProduct,
+	)
 	// api.Get("/me")
 }
 
-func RegisterFollowRoutes(app *fiber.App, db *gorm.DB) {
+func RegisterFollowRoutes(app *fiber.App, db *gorm.DB, tokens *token.Tokens) {
 	api := app.Group("/api/follow")
+	authRequired := newAuthRequired(db, tokens)
 	followRepo := follow.NewFollowersRepository(db)
 	followService := follow.NewFollowService(followRepo)
 	followHandler := follow.NewFollowController(followService)
 
-	api.Post("/merchant/:id", middleware.AuthRequired, followHandler.FollowMerchant)
-	api.Delete("/merchant/:id", middleware.AuthRequired, followHandler.UnfollowMerchant)
-	api.Get("/merchant/:id/status", middleware.AuthRequired, followHandler.GetMerchantFollowStatus)
-	// api.Get("/merchant", middleware.AuthRequired, follow)
+	api.Post("/merchant/:id", authRequired, followHandler.FollowMerchant)
+	api.Delete("/merchant/:id", authRequired, followHandler.UnfollowMerchant)
+	api.Get("/merchant/:id/status", authRequired, followHandler.GetMerchantFollowStatus)
+	// api.Get("/merchant", authRequired, follow)
+}
+
+func newTransactionService(db *gorm.DB, gateway payment.PaymentGateway) transactions.TransactionService {
+	return transactions.NewTransactionService(
+		db,
+		transactions.NewTransactionRepository(db),
+		transactions.NewTransactionItemRepository(db),
+		products.NewProductRepository(db),
+		inventory.NewStockMovementRepository(db),
+		inventory.NewStockReservationRepository(db),
+		transactions.NewPaymentNotificationRepository(db),
+		gateway,
+	)
 }
 
-func RegisterTransactionRoutes(app *fiber.App, db *gorm.DB) {
+// StartTransactionExpiry menjalankan pembatalan transaksi PENDING yang
+// terbengkalai, aman dijalankan di banyak instance sekaligus
+func StartTransactionExpiry(ctx context.Context, db *gorm.DB, cfg *config.Config, gateway payment.PaymentGateway) {
+	if cfg.TransactionExpiryInterval == 0 {
+		return
+	}
+
+	scheduler := transactions.NewExpiryScheduler(db, newTransactionService(db, gateway), cfg.PendingTransactionTTL, cfg.TransactionExpiryInterval)
+	scheduler.Start(ctx)
+}
+
+func RegisterTransactionRoutes(app *fiber.App, db *gorm.DB, cfg *config.Config, tokens *token.Tokens, gateway payment.PaymentGateway) {
 	api := app.Group("/api/transactions")
+	authRequired := newAuthRequired(db, tokens)
+	verifiedEmailRequired := newVerifiedEmailRequired(db, cfg, tokens)
 
 	transactionRepo := transactions.NewTransactionRepository(db)
-	transactionItemRepo := transactions.NewTransactionItemRepository(db)
-	productRepo := products.NewProductRepository(db)
-	stockMovementRepo := inventory.NewStockMovementRepository(db)
+	merchantRepo := merchant.NewMerchantRepository(db)
+	memberRepo := merchant.NewMerchantMemberRepository(db)
 
-	transactionService := transactions.NewTransactionService(db, transactionRepo, transactionItemRepo, productRepo, stockMovementRepo)
+	transactionService := newTransactionService(db, gateway)
 	transactionHandler := transactions.NewTransactionHandler(transactionService)
+	refundService := transactions.NewRefundService(db, transactionRepo, transactions.NewRefundRepository(db), inventory.NewStockMovementRepository(db), gateway)
+	refundHandler := transactions.NewRefundHandler(refundService)
+	fulfillmentHandler := transactions.NewFulfillmentHandler(transactions.NewFulfillmentService(transactionRepo, refundService))
+
+	api.Get("/history", authRequired, middleware.RequirePermission(permission.TransactionsRead), transactionHandler.GetTransactionsByUserID)
+	api.Get("/review", authRequired, middleware.RequirePermission(permission.PaymentsReview), transactionHandler.GetChallengedTransactions)
+	api.Get(
+		"/merchant/:merchant_id/review",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantTransactionsReview, merchantFromParam(merchantRepo, memberRepo, "merchant_id")),
+		transactionHandler.GetMerchantChallengedTransactions,
+	)
+	api.Get(
+		"/merchant/:merchant_id",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantTransactionsRead, merchantFromParam(merchantRepo, memberRepo, "merchant_id")),
+		transactionHandler.GetTransactionsByMerchantID,
+	)
+	api.Get(
+		"/:transaction_id",
+		authRequired,
+		middleware.RequirePermission(permission.TransactionsRead, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+		transactionHandler.GetTransactionDetail,
+	)
+	api.Get(
+		"/:transaction_id/refunds",
+		authRequired,
+		middleware.RequirePermission(permission.TransactionsRead, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+		refundHandler.GetRefunds,
+	)
 
-	api.Get("/history", middleware.AuthRequired, transactionHandler.GetTransactionsByUserID)
-	api.Get("/merchant/:merchant_id", middleware.AuthRequired, transactionHandler.GetTransactionsByMerchantID)
-	api.Get("/:transaction_id", middleware.AuthRequired, transactionHandler.GetTransactionDetail)
+	api.Post(
+		"/",
+		authRequired,
+		middleware.RequirePermission(permission.TransactionsCreate),
+		verifiedEmailRequired,
+		transactionHandler.CreateTransaction,
+	)
+	api.Post(
+		"/:idempotency_key",
+		authRequired,
+		middleware.RequirePermission(permission.TransactionsCreate),
+		verifiedEmailRequired,
+		transactionHandler.ResumeTransaction,
+	)
+	api.Post(
+		"/:transaction_id/approve",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantTransactionsReview, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+		transactionHandler.ApproveChallenge,
+	)
+	api.Post(
+		"/:transaction_id/deny",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantTransactionsReview, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+		transactionHandler.DenyChallenge,
+	)
+	api.Post(
+		"/:transaction_id/cancel",
+		authRequired,
+		middleware.RequirePermission(permission.TransactionsRead, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+		transactionHandler.CancelTransaction,
+	)
+	api.Post(
+		"/:transaction_id/refunds",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantTransactionsRefund, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+		refundHandler.CreateRefund,
+	)
+	api.Post(
+		"/:transaction_id/fulfillment",
+		authRequired,
+		middleware.RequirePermission(permission.MerchantTransactionsFulfill, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+		fulfillmentHandler.AdvanceFulfillment,
+	)
+	api.Post("/webhook/"+gateway.Name(), transactionHandler.HandlePaymentWebhook)
 
-	api.Post("/", middleware.AuthRequired, transactionHandler.CreateTransaction)
-	api.Post("/:idempotency_key", middleware.AuthRequired, transactionHandler.ResumeTransaction)
-	api.Post("/webhook/midtrans", transactionHandler.HandleMidtransWebhook)
+	// simulasi pembayaran hanya ada di fake gateway dan hanya di development,
+	// config sudah menolak fake gateway di luar development
+	if _, ok := gateway.(payment.Simulator); ok && cfg.IsDevelopment() {
+		api.Post(
+			"/:transaction_id/simulate/:status",
+			authRequired,
+			middleware.RequirePermission(permission.TransactionsRead, transactionFromParam(transactionRepo, memberRepo, "transaction_id")),
+			transactionHandler.SimulatePayment,
+		)
+	}
 }
 
-func RegisterStockMovementRoutes(app *fiber.App, db *gorm.DB) {
-	// api := app.Group("/api/stock-movements")
-	// stockMovementRepo := stockmovements.NewStockMovementRepository(db)
-	// stockMovementService := stockmovements.NewStockMovementService(stockMovementRepo)
-	// stockMovementHandler := stockmovements.NewStockMovementHandler(stockMovementService)
+func RegisterPaymentRoutes(app *fiber.App, cfg *config.Config, gateway payment.PaymentGateway) {
+	api := app.Group("/api/payments")
+	paymentConfigHandler := transactions.NewPaymentConfigHandler(gateway.Name(), cfg.Midtrans)
+
+	api.Get("/config", paymentConfigHandler.GetPaymentConfig)
+}
+
+func RegisterStockMovementRoutes(app *fiber.App, db *gorm.DB, tokens *token.Tokens) {
+	api := app.Group("/api/inventory")
+	authRequired := newAuthRequiredWithAPIKey(db, tokens)
+	stockMovementRepo := inventory.NewStockMovementRepository(db)
+	stockMovementService := inventory.NewStockMovementService(db, stockMovementRepo)
+	stockMovementHandler := inventory.NewStockMovementHandler(stockMovementService)
+	merchantRepo := merchant.NewMerchantRepository(db)
+	memberRepo := merchant.NewMerchantMemberRepository(db)
+
+	canWriteInventory := middleware.RequirePermission(permission.MerchantInventoryWrite, merchantFromParam(merchantRepo, memberRepo, "merchant_id"))
+
+	api.Post("/:merchant_id/stock-in", authRequired, canWriteInventory, stockMovementHandler.AddStockIn)
+	api.Post("/:merchant_id/stock-out", authRequired, canWriteInventory, stockMovementHandler.AddStockOut)
+}
+
+func RegisterPrivacyRoutes(app *fiber.App, db *gorm.DB, cfg *config.Config, tokens *token.Tokens) {
+	api := app.Group("/api/privacy")
+	authRequired := newAuthRequired(db, tokens)
+	privacyRepo := privacy.NewPrivacyRepository(db)
+	privacyService := privacy.NewPrivacyService(privacyRepo, cfg.DataExportDir)
+	privacyHandler := privacy.NewPrivacyHandler(privacyService, tokens.Cookies)
+
+	api.Post("/exports", authRequired, privacyHandler.RequestExport)
+	api.Get("/exports", authRequired, privacyHandler.GetExports)
+	api.Get("/exports/:id/download", authRequired, privacyHandler.DownloadExport)
+	api.Delete("/account", authRequired, privacyHandler.DeleteAccount)
 }
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-fiber-api/internal/config"
+	"go-fiber-api/internal/util/payment"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCSRFExemptsGatewayWebhook(t *testing.T) {
+	tokens := &token.Tokens{Cookies: token.NewCookiePolicy(config.CookieConfig{AccessTokenName: "token", CSRFTokenName: "csrf_token"})}
+
+	app := fiber.New()
+	RegisterCSRFProtection(app, tokens, payment.NewFakeGateway("http://localhost"))
+	app.Post("/api/transactions/webhook/*", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{path: "/api/transactions/webhook/fake", status: http.StatusOK},
+		// webhook gateway lain tidak ikut dikecualikan
+		{path: "/api/transactions/webhook/midtrans", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// dengan cookie auth, request wajib CSRF kecuali path-nya dikecualikan
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req.Header.Set(fiber.HeaderCookie, "token=a")
+
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"go-fiber-api/internal/features/merchant"
+	"go-fiber-api/internal/features/transactions"
+	"go-fiber-api/internal/middleware"
+	"go-fiber-api/internal/util/permission"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func parseUUIDParam(c *fiber.Ctx, param string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params(param))
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, middleware.ErrInvalidResource
+	}
+
+	return id, nil
+}
+
+// merchantPermissions mengembalikan permission member aktif terhadap merchant.
+// Request dengan API key hanya mendapat scope key, dan hanya untuk merchant
+// pemilik key tersebut
+func merchantPermissions(
+	c *fiber.Ctx,
+	memberRepo merchant.MerchantMemberRepository,
+	merchantID uuid.UUID,
+	userID uuid.UUID,
+) ([]string, error) {
+	if apiKey, ok := middleware.APIKeyFromContext(c); ok {
+		if apiKey.MerchantID != merchantID {
+			return nil, nil
+		}
+		return apiKey.Scopes, nil
+	}
+
+	member, err := memberRepo.FindActiveMembership(merchantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member == nil {
+		return nil, nil
+	}
+
+	return permission.ForMerchantRole(member.Role), nil
+}
+
+// merchantFromParam memberi permission sesuai role membership user
+// di merchant pada param
+func merchantFromParam(
+	merchantRepo merchant.MerchantRepository,
+	memberRepo merchant.MerchantMemberRepository,
+	param string,
+) middleware.ResourceResolver {
+	return func(c *fiber.Ctx, userID uuid.UUID) ([]string, error) {
+		merchantID, err := parseUUIDParam(c, param)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = merchantRepo.GetMerchantById(merchantID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, middleware.ErrResourceNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return merchantPermissions(c, memberRepo, merchantID, userID)
+	}
+}
+
+// ownMerchant dipakai untuk route lama tanpa merchant id,
+// merchant diambil dari merchant milik user
+func ownMerchant(
+	merchantRepo merchant.MerchantRepository,
+	memberRepo merchant.MerchantMemberRepository,
+) middleware.ResourceResolver {
+	return func(c *fiber.Ctx, userID uuid.UUID) ([]string, error) {
+		m, err := merchantRepo.GetMyMerchant(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if m == nil {
+			return nil, nil
+		}
+
+		return merchantPermissions(c, memberRepo, m.ID, userID)
+	}
+}
+
+// transactionFromParam: pembeli boleh membaca transaksinya sendiri,
+// member merchant boleh membaca transaksi merchant sesuai role-nya
+func transactionFromParam(
+	transactionRepo transactions.TransactionRepository,
+	memberRepo merchant.MerchantMemberRepository,
+	param string,
+) middleware.ResourceResolver {
+	return func(c *fiber.Ctx, userID uuid.UUID) ([]string, error) {
+		transactionID, err := parseUUIDParam(c, param)
+		if err != nil {
+			return nil, err
+		}
+
+		trx, err := transactionRepo.FindByID(transactionID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, middleware.ErrResourceNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, isAPIKey := middleware.APIKeyFromContext(c); !isAPIKey && trx.UserID == userID {
+			return []string{permission.TransactionsRead}, nil
+		}
+
+		granted, err := merchantPermissions(c, memberRepo, trx.MerchantID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if permission.Has(granted, permission.MerchantTransactionsRead) {
+			granted = append(granted, permission.TransactionsRead)
+		}
+
+		return granted, nil
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-fiber-api/internal/config"
+	"go-fiber-api/internal/middleware"
+	"go-fiber-api/internal/util/permission"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type staticAPIKeys map[string]*middleware.APIKeyPrincipal
+
+func (k staticAPIKeys) ValidateAPIKey(key string) (*middleware.APIKeyPrincipal, error) {
+	principal, ok := k[key]
+	if !ok {
+		return nil, errors.New("api key not found")
+	}
+	return principal, nil
+}
+
+func newAPIKeyApp(apiKeys middleware.APIKeyValidator, required string) *fiber.App {
+	tokens := &token.Tokens{Cookies: token.NewCookiePolicy(config.CookieConfig{AccessTokenName: "token"})}
+
+	// resolver tanpa lookup merchant, API key tidak memakai memberRepo
+	resolver := func(c *fiber.Ctx, userID uuid.UUID) ([]string, error) {
+		merchantID, err := parseUUIDParam(c, "merchant_id")
+		if err != nil {
+			return nil, err
+		}
+		return merchantPermissions(c, nil, merchantID, userID)
+	}
+
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+
+	app := fiber.New()
+	app.Post("/merchants/:merchant_id/products", middleware.AuthRequiredWithAPIKey(tokens, nil, apiKeys), middleware.RequirePermission(required, resolver), ok)
+	app.Post("/session-only", middleware.AuthRequired(tokens, nil), ok)
+
+	return app
+}
+
+func TestAPIKeyScopeEnforcement(t *testing.T) {
+	merchantID := uuid.New()
+
+	writer, _, _ := token.GenerateAPIKey()
+	reader, _, _ := token.GenerateAPIKey()
+	unknown, _, _ := token.GenerateAPIKey()
+
+	apiKeys := staticAPIKeys{
+		writer: {KeyID: uuid.New(), MerchantID: merchantID, CreatedBy: uuid.New(), Scopes: []string{permission.MerchantProductsWrite}},
+		reader: {KeyID: uuid.New(), MerchantID: merchantID, CreatedBy: uuid.New(), Scopes: []string{permission.MerchantProductsRead}},
+	}
+
+	app := newAPIKeyApp(apiKeys, permission.MerchantProductsWrite)
+
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+	}{
+		{name: "scope granted", path: "/merchants/" + merchantID.String() + "/products", key: writer, status: http.StatusOK},
+		{name: "scope missing", path: "/merchants/" + merchantID.String() + "/products", key: reader, status: http.StatusForbidden},
+		{name: "other merchant", path: "/merchants/" + uuid.NewString() + "/products", key: writer, status: http.StatusForbidden},
+		{name: "unknown key", path: "/merchants/" + merchantID.String() + "/products", key: unknown, status: http.StatusUnauthorized},
+		{name: "no credentials", path: "/merchants/" + merchantID.String() + "/products", status: http.StatusUnauthorized},
+		{name: "route without api keys", path: "/session-only", key: writer, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tt.key)
+			}
+
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+		})
+	}
+}
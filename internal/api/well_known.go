@@ -0,0 +1,17 @@
+package api
+
+import (
+	"go-fiber-api/internal/util/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterWellKnownRoutes mempublikasikan public key JWT supaya service
+// lain bisa memverifikasi access token tanpa tahu secret kita
+func RegisterWellKnownRoutes(app *fiber.App, keys *token.KeySet) {
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+
+		return c.JSON(keys.JWKS())
+	})
+}
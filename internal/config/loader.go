@@ -1,26 +1,262 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
-func Get() *Config {
-	err := godotenv.Load()
+const defaultConfigFile = "config.yaml"
+
+// Load membaca konfigurasi sekali saat startup lalu hasilnya di-inject ke
+// service. Prioritas: environment variable, .env, file YAML (CONFIG_FILE
+// atau config.yaml kalau ada), lalu default. .env dan YAML opsional
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("config: failed to load .env: %w", err)
+	}
+
+	src, err := newSource(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	apiBaseURL := src.getDefault("API_BASE_URL", "http://localhost:8080")
+	appEnv := strings.ToLower(src.getDefault("APP_ENV", "production"))
+
+	// log mailer hanya jadi default di development, di tempat lain lupa
+	// mengisi MAIL_DRIVER harus gagal saat startup
+	mailDriver := strings.ToLower(src.get("MAIL_DRIVER"))
+	if mailDriver == "" && appEnv == "development" {
+		mailDriver = "log"
+	}
+
+	cfg := &Config{
+		Database:             src.get("DATABASE_URL"),
+		Port:                 src.getDefault("PORT", "8080"),
+		JwtKey:               src.get("JWT_SECRET_KEY"),
+		JwtKeysDir:           src.get("JWT_KEYS_DIR"),
+		JwtActiveKID:         src.get("JWT_ACTIVE_KID"),
+		JwtLegacyAcceptUntil: src.getTime("JWT_LEGACY_ACCEPT_UNTIL"),
+		SupabaseURL:          src.get("SUPABASE_URL"),
+		SupabaseServiceKey:   src.get("SUPABASE_SERVICE_KEY"),
+
+		AppEnv:        appEnv,
+		AppBaseURL:    src.getDefault("APP_BASE_URL", "http://localhost:3000"),
+		MailDriver:    mailDriver,
+		MailFrom:      src.getDefault("MAIL_FROM", "no-reply@localhost"),
+		MailOutputDir: src.get("MAIL_OUTPUT_DIR"),
+		SMTPHost:      src.get("SMTP_HOST"),
+		SMTPPort:      src.getDefault("SMTP_PORT", "587"),
+		SMTPUsername:  src.get("SMTP_USERNAME"),
+		SMTPPassword:  src.get("SMTP_PASSWORD"),
+
+		RequireVerifiedEmail: src.getBool("REQUIRE_VERIFIED_EMAIL", false),
+
+		PaymentDriver: strings.ToLower(src.getDefault("PAYMENT_DRIVER", "midtrans")),
+		Midtrans: MidtransConfig{
+			ServerKey:       src.get("MIDTRANS_SERVER_KEY"),
+			ClientKey:       src.get("MIDTRANS_CLIENT_KEY"),
+			MerchantID:      src.get("MIDTRANS_MERCHANT_ID"),
+			Environment:     strings.ToLower(src.getDefault("MIDTRANS_ENVIRONMENT", "sandbox")),
+			EnabledPayments: src.getList("MIDTRANS_ENABLED_PAYMENTS"),
+			Expiry:          src.getDuration("MIDTRANS_EXPIRY", 0),
+			NotificationURL: src.get("MIDTRANS_NOTIFICATION_URL"),
+			FinishURL:       src.get("MIDTRANS_FINISH_URL"),
+			CustomFields:    src.getList("MIDTRANS_CUSTOM_FIELDS"),
+			VerifyStatus:    src.getBool("MIDTRANS_VERIFY_STATUS", true),
+		},
+
+		PendingTransactionTTL:     src.getDuration("TRANSACTION_PENDING_TTL", 24*time.Hour),
+		TransactionExpiryInterval: src.getDuration("TRANSACTION_EXPIRY_INTERVAL", time.Minute),
+
+		// default Secure mengikuti skema API_BASE_URL, jadi localhost (http)
+		// tetap jalan tanpa konfigurasi tambahan
+		Cookie: CookieConfig{
+			Domain:           src.get("COOKIE_DOMAIN"),
+			Secure:           src.getBool("COOKIE_SECURE", strings.HasPrefix(apiBaseURL, "https://")),
+			SameSite:         strings.ToLower(src.getDefault("COOKIE_SAMESITE", "lax")),
+			HostPrefix:       src.getBool("COOKIE_HOST_PREFIX", false),
+			AccessTokenName:  src.getDefault("ACCESS_TOKEN_COOKIE", "token"),
+			RefreshTokenName: src.getDefault("REFRESH_TOKEN_COOKIE", "refresh_token"),
+			CSRFTokenName:    src.getDefault("CSRF_TOKEN_COOKIE", "csrf_token"),
+		},
+
+		DataExportDir: src.getDefault("DATA_EXPORT_DIR", filepath.Join(os.TempDir(), "go-fiber-api-exports")),
+
+		APIBaseURL:    apiBaseURL,
+		OIDCProviders: loadOIDCProviders(src),
+	}
+
+	if err := errors.Join(append(src.errs, cfg.validate()...)...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return cfg, nil
+}
+
+// loadOIDCProviders membaca OIDC_PROVIDERS=google,mock lalu
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, dst untuk tiap provider
+func loadOIDCProviders(src *source) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+
+	for _, name := range strings.Split(src.get("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		issuer := src.get(prefix + "ISSUER")
+		if issuer == "" && name == "google" {
+			issuer = "https://accounts.google.com"
+		}
+
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       issuer,
+			ClientID:     src.get(prefix + "CLIENT_ID"),
+			ClientSecret: src.get(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(strings.ReplaceAll(src.get(prefix+"SCOPES"), ",", " ")),
+		})
+	}
+
+	return providers
+}
+
+// source menggabungkan environment variable dengan isi file YAML.
+// Key YAML bertingkat diratakan jadi nama env, misalnya
+// oidc.google.client_id menjadi OIDC_GOOGLE_CLIENT_ID
+type source struct {
+	file map[string]string
+	errs []error
+}
+
+func newSource(path string) (*source, error) {
+	src := &source{file: map[string]string{}}
+
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return src, nil
+	}
 
 	if err != nil {
-		panic("Error loading .env file")
+		return nil, fmt.Errorf("config: failed to read %s: %w", path, err)
 	}
 
-	return &Config{
-		Database:           os.Getenv("DATABASE_URL"),
-		Port:               os.Getenv("PORT"),
-		JwtKey:             os.Getenv("JWT_SECRET_KEY"),
-		SupabaseURL:        os.Getenv("SUPABASE_URL"),
-		SupabaseServiceKey: os.Getenv("SUPABASE_SERVICE_KEY"),
-		MidtransServerKey:  os.Getenv("MIDTRANS_SERVER_KEY"),
-		MidtransClientKey:  os.Getenv("MIDTRANS_CLIENT_KEY"),
-		MidtransMerchantID: os.Getenv("MIDTRANS_MERCHANT_ID"),
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("config: failed to parse %s: %w", path, err)
+	}
+
+	flatten("", raw, src.file)
+
+	return src, nil
+}
+
+func flatten(prefix string, values map[string]interface{}, out map[string]string) {
+	for key, value := range values {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(name, v, out)
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[name] = strings.Join(items, ",")
+		case nil:
+			out[name] = ""
+		default:
+			out[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+func (s *source) get(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return s.file[key]
+}
+
+func (s *source) getDefault(key, fallback string) string {
+	if value := s.get(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getList membaca nilai dipisah koma, list YAML sudah digabung oleh flatten
+func (s *source) getList(key string) []string {
+	var values []string
+
+	for _, value := range strings.Split(s.get(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func (s *source) getDuration(key string, fallback time.Duration) time.Duration {
+	raw := s.get(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be a duration such as 30m or 24h, got %q", key, raw))
+		return fallback
+	}
+	return value
+}
+
+func (s *source) getTime(key string) time.Time {
+	raw := s.get(key)
+	if raw == "" {
+		return time.Time{}
+	}
+
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be an RFC3339 time such as 2026-10-18T00:00:00Z, got %q", key, raw))
+		return time.Time{}
+	}
+	return value
+}
+
+func (s *source) getBool(key string, fallback bool) bool {
+	raw := s.get(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be a boolean, got %q", key, raw))
+		return fallback
 	}
+	return value
 }
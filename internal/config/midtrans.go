@@ -4,9 +4,22 @@ import (
 	"github.com/midtrans/midtrans-go"
 )
 
-func InitMidtrans() {
-	cfg := Get()
+const (
+	midtransSnapJSSandbox    = "https://app.sandbox.midtrans.com/snap/snap.js"
+	midtransSnapJSProduction = "https://app.midtrans.com/snap/snap.js"
+)
+
+func (m MidtransConfig) EnvironmentType() midtrans.EnvironmentType {
+	if m.Environment == "production" {
+		return midtrans.Production
+	}
+	return midtrans.Sandbox
+}
 
-	midtrans.ServerKey = cfg.MidtransServerKey
-	midtrans.Environment = midtrans.Sandbox
+// SnapJSURL adalah script Snap yang harus dimuat frontend, beda per environment
+func (m MidtransConfig) SnapJSURL() string {
+	if m.Environment == "production" {
+		return midtransSnapJSProduction
+	}
+	return midtransSnapJSSandbox
 }
@@ -1,12 +1,88 @@
 package config
 
+import "time"
+
 type Config struct {
-	Database           string
-	Port               string
-	JwtKey             string
-	SupabaseURL        string
-	SupabaseServiceKey string
-	MidtransClientKey  string
-	MidtransServerKey  string
-	MidtransMerchantID string
+	Database     string
+	Port         string
+	JwtKey       string
+	JwtKeysDir   string
+	JwtActiveKID string
+	// JwtLegacyAcceptUntil adalah batas token HS256 lama masih diterima
+	// setelah pindah ke JWT_KEYS_DIR, wajib diisi kalau JWT_SECRET_KEY
+	// masih diset
+	JwtLegacyAcceptUntil time.Time
+	SupabaseURL          string
+	SupabaseServiceKey   string
+
+	// AppEnv development melonggarkan default yang tidak aman untuk
+	// production, misalnya MAIL_DRIVER=log dan PAYMENT_DRIVER=fake
+	AppEnv        string
+	AppBaseURL    string
+	MailDriver    string
+	MailFrom      string
+	MailOutputDir string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+
+	RequireVerifiedEmail bool
+
+	// PaymentDriver memilih payment gateway: midtrans atau fake (hanya
+	// development)
+	PaymentDriver string
+	Midtrans      MidtransConfig
+
+	// PendingTransactionTTL adalah batas umur transaksi PENDING sebelum
+	// dibatalkan scheduler, interval 0 mematikan scheduler
+	PendingTransactionTTL     time.Duration
+	TransactionExpiryInterval time.Duration
+
+	Cookie CookieConfig
+
+	DataExportDir string
+
+	APIBaseURL    string
+	OIDCProviders []OIDCProviderConfig
+}
+
+// MidtransConfig dipakai untuk Snap dan dikirim sebagian ke frontend
+// lewat GET /api/payments/config (hanya client key, bukan server key)
+type MidtransConfig struct {
+	ServerKey   string
+	ClientKey   string
+	MerchantID  string
+	Environment string
+
+	EnabledPayments []string
+	// Expiry 0 berarti memakai pengaturan default di dashboard Midtrans
+	Expiry          time.Duration
+	NotificationURL string
+	FinishURL       string
+	CustomFields    []string
+
+	// VerifyStatus mengambil ulang status dari API Midtrans sebelum
+	// notifikasi webhook diproses, isi body tidak dipercaya begitu saja
+	VerifyStatus bool
+}
+
+// CookieConfig mengatur atribut cookie auth, lihat token.CookiePolicy
+type CookieConfig struct {
+	Domain     string
+	Secure     bool
+	SameSite   string
+	HostPrefix bool
+
+	AccessTokenName  string
+	RefreshTokenName string
+	CSRFTokenName    string
+}
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
 }
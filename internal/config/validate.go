@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validate mengembalikan semua kesalahan sekaligus supaya tidak perlu
+// restart berulang kali hanya untuk menemukan key berikutnya yang kurang
+func (c *Config) validate() []error {
+	var errs []error
+
+	type requiredKey struct {
+		key   string
+		value string
+	}
+
+	required := []requiredKey{
+		{"DATABASE_URL", c.Database},
+		{"SUPABASE_URL", c.SupabaseURL},
+		{"SUPABASE_SERVICE_KEY", c.SupabaseServiceKey},
+	}
+
+	// key Midtrans tidak dibutuhkan kalau memakai fake gateway
+	if c.PaymentDriver == "midtrans" {
+		required = append(required,
+			requiredKey{"MIDTRANS_SERVER_KEY", c.Midtrans.ServerKey},
+			requiredKey{"MIDTRANS_CLIENT_KEY", c.Midtrans.ClientKey},
+		)
+	}
+
+	for _, r := range required {
+		if r.value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", r.key))
+		}
+	}
+
+	if c.JwtKey == "" && c.JwtKeysDir == "" {
+		errs = append(errs, fmt.Errorf("JWT_SECRET_KEY or JWT_KEYS_DIR is required"))
+	}
+
+	// batas token HS256 lama harus tanggal tetap, kalau dihitung dari waktu
+	// startup batasnya mundur lagi setiap restart
+	if c.JwtKey != "" && c.JwtKeysDir != "" && c.JwtLegacyAcceptUntil.IsZero() {
+		errs = append(errs, fmt.Errorf("JWT_LEGACY_ACCEPT_UNTIL is required when both JWT_SECRET_KEY and JWT_KEYS_DIR are set"))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", c.Port))
+	}
+
+	for _, r := range []struct {
+		key   string
+		value string
+	}{
+		{"APP_BASE_URL", c.AppBaseURL},
+		{"API_BASE_URL", c.APIBaseURL},
+	} {
+		if u, err := url.Parse(r.value); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s must be an absolute URL, got %q", r.key, r.value))
+		}
+	}
+
+	switch c.PaymentDriver {
+	case "midtrans":
+		switch c.Midtrans.Environment {
+		case "sandbox":
+		case "production":
+			// key sandbox diawali "SB-", kemungkinan besar salah copy dari dashboard
+			if strings.HasPrefix(c.Midtrans.ServerKey, "SB-") || strings.HasPrefix(c.Midtrans.ClientKey, "SB-") {
+				errs = append(errs, fmt.Errorf("MIDTRANS_ENVIRONMENT=production cannot use sandbox keys"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("MIDTRANS_ENVIRONMENT must be sandbox or production, got %q", c.Midtrans.Environment))
+		}
+	case "fake":
+		// fake gateway membuka endpoint simulasi, pembeli bisa menandai
+		// pesanannya sendiri lunas
+		if !c.IsDevelopment() {
+			errs = append(errs, fmt.Errorf("PAYMENT_DRIVER=fake is only allowed when APP_ENV=development"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("PAYMENT_DRIVER must be midtrans or fake, got %q", c.PaymentDriver))
+	}
+
+	if c.Midtrans.Expiry != 0 && c.Midtrans.Expiry < time.Minute {
+		errs = append(errs, fmt.Errorf("MIDTRANS_EXPIRY must be at least 1m, got %s", c.Midtrans.Expiry))
+	}
+
+	if len(c.Midtrans.CustomFields) > 3 {
+		errs = append(errs, fmt.Errorf("MIDTRANS_CUSTOM_FIELDS accepts at most 3 values"))
+	}
+
+	if c.PendingTransactionTTL < time.Minute {
+		errs = append(errs, fmt.Errorf("TRANSACTION_PENDING_TTL must be at least 1m, got %s", c.PendingTransactionTTL))
+	}
+
+	// transaksi tidak boleh dibatalkan selagi halaman pembayaran masih berlaku
+	if c.PaymentDriver == "midtrans" && c.Midtrans.Expiry > c.PendingTransactionTTL {
+		errs = append(errs, fmt.Errorf("TRANSACTION_PENDING_TTL (%s) must not be shorter than MIDTRANS_EXPIRY (%s)", c.PendingTransactionTTL, c.Midtrans.Expiry))
+	}
+
+	if c.TransactionExpiryInterval < 0 {
+		errs = append(errs, fmt.Errorf("TRANSACTION_EXPIRY_INTERVAL must not be negative, got %s", c.TransactionExpiryInterval))
+	}
+
+	switch c.AppEnv {
+	case "development", "staging", "production":
+	default:
+		errs = append(errs, fmt.Errorf("APP_ENV must be development, staging or production, got %q", c.AppEnv))
+	}
+
+	switch c.MailDriver {
+	case "":
+		errs = append(errs, fmt.Errorf("MAIL_DRIVER is required unless APP_ENV=development"))
+	case "log":
+	case "smtp":
+		if c.SMTPHost == "" {
+			errs = append(errs, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER=smtp"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_DRIVER must be log or smtp, got %q", c.MailDriver))
+	}
+
+	switch c.Cookie.SameSite {
+	case "lax", "strict", "none":
+	default:
+		errs = append(errs, fmt.Errorf("COOKIE_SAMESITE must be lax, strict or none, got %q", c.Cookie.SameSite))
+	}
+
+	for _, p := range c.OIDCProviders {
+		if p.Issuer == "" || p.ClientID == "" {
+			errs = append(errs, fmt.Errorf("OIDC provider %q needs ISSUER and CLIENT_ID", p.Name))
+		}
+	}
+
+	return errs
+}
+
+func (c *Config) IsDevelopment() bool {
+	return c.AppEnv == "development"
+}
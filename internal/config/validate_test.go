@@ -0,0 +1,97 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// loadWith menjalankan Load dengan konfigurasi minimal yang valid,
+// ditimpa oleh env
+func loadWith(t *testing.T, env map[string]string) (*Config, error) {
+	t.Helper()
+
+	base := map[string]string{
+		"CONFIG_FILE":          "",
+		"DATABASE_URL":         "postgres://localhost/test",
+		"SUPABASE_URL":         "http://localhost:54321",
+		"SUPABASE_SERVICE_KEY": "service-key",
+		"JWT_SECRET_KEY":       "secret",
+		"APP_ENV":              "production",
+		"MAIL_DRIVER":          "log",
+		"PAYMENT_DRIVER":       "midtrans",
+		"MIDTRANS_SERVER_KEY":  "SB-server",
+		"MIDTRANS_CLIENT_KEY":  "SB-client",
+	}
+
+	for key, value := range env {
+		base[key] = value
+	}
+
+	for key, value := range base {
+		t.Setenv(key, value)
+	}
+
+	return Load()
+}
+
+func TestFakePaymentDriverOnlyInDevelopment(t *testing.T) {
+	tests := []struct {
+		appEnv  string
+		wantErr bool
+	}{
+		{appEnv: "development"},
+		{appEnv: "staging", wantErr: true},
+		{appEnv: "production", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.appEnv, func(t *testing.T) {
+			cfg, err := loadWith(t, map[string]string{"APP_ENV": tt.appEnv, "PAYMENT_DRIVER": "fake"})
+
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "PAYMENT_DRIVER=fake") {
+					t.Fatalf("Load error = %v, want PAYMENT_DRIVER=fake error", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			if !cfg.IsDevelopment() || cfg.PaymentDriver != "fake" {
+				t.Fatalf("AppEnv/PaymentDriver = %s/%s, want development/fake", cfg.AppEnv, cfg.PaymentDriver)
+			}
+		})
+	}
+}
+
+func TestAppEnvDefaultsToProduction(t *testing.T) {
+	cfg, err := loadWith(t, map[string]string{"APP_ENV": ""})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.IsDevelopment() {
+		t.Fatalf("AppEnv = %s, want production", cfg.AppEnv)
+	}
+}
+
+func TestLegacyJWTCutoffRequiredWithKeysDir(t *testing.T) {
+	if _, err := loadWith(t, map[string]string{"JWT_KEYS_DIR": "keys"}); err == nil || !strings.Contains(err.Error(), "JWT_LEGACY_ACCEPT_UNTIL") {
+		t.Fatalf("Load error = %v, want JWT_LEGACY_ACCEPT_UNTIL error", err)
+	}
+
+	cfg, err := loadWith(t, map[string]string{"JWT_KEYS_DIR": "keys", "JWT_LEGACY_ACCEPT_UNTIL": "2026-11-01T00:00:00Z"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.JwtLegacyAcceptUntil.IsZero() {
+		t.Fatal("JwtLegacyAcceptUntil is not parsed")
+	}
+
+	if _, err := loadWith(t, map[string]string{"JWT_KEYS_DIR": "keys", "JWT_SECRET_KEY": ""}); err != nil {
+		t.Fatalf("Load without legacy secret: %v", err)
+	}
+}
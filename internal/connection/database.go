@@ -9,6 +9,7 @@ import (
 	"go-fiber-api/internal/features/follow"
 	"go-fiber-api/internal/features/inventory"
 	"go-fiber-api/internal/features/merchant"
+	"go-fiber-api/internal/features/privacy"
 	"go-fiber-api/internal/features/products"
 	"go-fiber-api/internal/features/transactions"
 
@@ -16,8 +17,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func ConnectDB() (*gorm.DB, error) {
-	cfg := config.Get()
+func ConnectDB(cfg *config.Config) (*gorm.DB, error) {
 
 	if cfg.Database == "" {
 		return nil, fmt.Errorf("database connection string is empty")
@@ -41,16 +41,54 @@ func migrate(db *gorm.DB) error {
 
 	if err := db.AutoMigrate(
 		&auth.User{},
+		&auth.Session{},
+		&auth.RefreshToken{},
+		&auth.PasswordResetToken{},
+		&auth.UserTwoFactor{},
+		&auth.RecoveryCode{},
+		&auth.LoginChallenge{},
+		&auth.LoginThrottle{},
+		&auth.AuditLog{},
+		&auth.UserIdentity{},
+		&auth.OIDCAuthRequest{},
 		&merchant.Merchant{},
+		&merchant.MerchantMember{},
+		&merchant.MerchantAPIKey{},
 		&products.Product{},
 		&follow.Follow{},
 		&transactions.Transaction{},
 		&transactions.TransactionItem{},
+		&transactions.RejectedPaymentNotification{},
+		&transactions.Refund{},
+		&transactions.RefundItem{},
 		&inventory.StockMovement{},
+		&inventory.StockReservation{},
+		&privacy.DataExport{},
 	); err != nil {
 		return fmt.Errorf("auto migrate failed: %w", err)
 	}
 
+	if err := backfillMerchantOwners(db); err != nil {
+		return fmt.Errorf("backfill merchant owners failed: %w", err)
+	}
+
 	log.Println("database migrated successfully")
 	return nil
 }
+
+// backfillMerchantOwners membuat membership owner untuk merchant
+// yang dibuat sebelum tabel merchant_members ada
+func backfillMerchantOwners(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO merchant_members (merchant_id, user_id, email, role, status, accepted_at, created_at, updated_at)
+		SELECT merchants.id, merchants.user_id, users.email, 'owner', 'ACTIVE', NOW(), NOW(), NOW()
+		FROM merchants
+		JOIN users ON users.id = merchants.user_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM merchant_members
+			WHERE merchant_members.merchant_id = merchants.id
+			AND merchant_members.role = 'owner'
+		)
+		ON CONFLICT DO NOTHING
+	`).Error
+}
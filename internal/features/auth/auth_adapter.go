@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"go-fiber-api/internal/features/merchant"
+
+	"github.com/google/uuid"
+)
+
+type UserServiceAdapter struct {
+	service AuthService
+}
+
+func NewUserServiceAdapter(service AuthService) merchant.UserServiceContract {
+	return &UserServiceAdapter{
+		service: service,
+	}
+}
+
+func (a *UserServiceAdapter) GetUserByID(id uuid.UUID) (*merchant.UserInfo, error) {
+	user, err := a.service.GetUser(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &merchant.UserInfo{
+		ID:    user.ID,
+		Email: user.Email,
+	}, nil
+}
@@ -13,8 +13,18 @@ type LoginRequest struct {
 }
 
 type AuthResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt string    `json:"created_at"`
-	UpdatedAt string    `json:"updated_at"`
+	ID            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	PendingEmail  string    `json:"pending_email,omitempty"`
+
+	DisplayName      string `json:"display_name"`
+	PhoneNumber      string `json:"phone_number"`
+	AvatarURL        string `json:"avatar_url"`
+	Locale           string `json:"locale"`
+	MarketingConsent bool   `json:"marketing_consent"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 }
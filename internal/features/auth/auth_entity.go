@@ -2,14 +2,32 @@ package auth
 
 import (
 	"database/sql"
+	"go-fiber-api/internal/util/permission"
 
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Email    string    `gorm:"uniqueIndex;not null"`
-	Password string    `gorm:"not null"`
+	ID       uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Email    string          `gorm:"uniqueIndex;not null"`
+	Password string          `gorm:"not null"`
+	Role     permission.Role `gorm:"type:varchar(30);not null;default:'customer'"`
+
+	EmailVerifiedAt    sql.NullTime
+	VerificationSentAt sql.NullTime
+	// PendingEmail menunggu diverifikasi sebelum menggantikan Email
+	PendingEmail string `gorm:"type:varchar(255)"`
+
+	DisplayName        string `gorm:"type:varchar(100)"`
+	PhoneNumber        string `gorm:"type:varchar(20)"`
+	AvatarURL          string `gorm:"type:text"`
+	Locale             string `gorm:"type:varchar(20);not null;default:'id'"`
+	MarketingConsent   bool   `gorm:"not null;default:false"`
+	MarketingConsentAt sql.NullTime
+
+	// AnonymizedAt terisi kalau user menghapus akunnya, row tetap ada
+	// supaya transaksi lama masih punya referensi
+	AnonymizedAt sql.NullTime
 
 	CreatedAt sql.NullTime
 	UpdatedAt sql.NullTime
@@ -1,16 +1,30 @@
 package auth
 
 import (
+	"errors"
 	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/permission"
 	"go-fiber-api/internal/util/token"
 	"go-fiber-api/internal/util/validation"
+	"log"
+	"math"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type authHandler struct {
-	authService AuthService
+	authService          AuthService
+	sessionService       SessionService
+	passwordResetService PasswordResetService
+	verificationService  EmailVerificationService
+	twoFactorService     TwoFactorService
+	loginThrottle        LoginThrottleService
+	tokens               *token.Tokens
 }
 
 type Handler interface {
@@ -18,11 +32,38 @@ type Handler interface {
 	LoginUser(c *fiber.Ctx) error
 	GetUser(c *fiber.Ctx) error
 	LogoutUser(c *fiber.Ctx) error
+	RefreshToken(c *fiber.Ctx) error
+	GetSessions(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	RevokeOtherSessions(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	ResendVerification(c *fiber.Ctx) error
+	LoginTwoFactor(c *fiber.Ctx) error
+	SetupTwoFactor(c *fiber.Ctx) error
+	EnableTwoFactor(c *fiber.Ctx) error
+	DisableTwoFactor(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
 }
 
-func NewHandler(service AuthService) *authHandler {
+func NewHandler(
+	service AuthService,
+	sessionService SessionService,
+	passwordResetService PasswordResetService,
+	verificationService EmailVerificationService,
+	twoFactorService TwoFactorService,
+	loginThrottle LoginThrottleService,
+	tokens *token.Tokens,
+) *authHandler {
 	return &authHandler{
-		authService: service,
+		authService:          service,
+		sessionService:       sessionService,
+		passwordResetService: passwordResetService,
+		verificationService:  verificationService,
+		twoFactorService:     twoFactorService,
+		loginThrottle:        loginThrottle,
+		tokens:               tokens,
 	}
 }
 
@@ -39,12 +80,17 @@ func (h *authHandler) RegisterUser(c *fiber.Ctx) error {
 		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
 	}
 
-	err := h.authService.RegisterUser(&req)
+	user, err := h.authService.RegisterUser(&req)
 
 	if err != nil {
 		return response.Fail(c, fiber.StatusInternalServerError, "failed to register user")
 	}
 
+	// gagal kirim email tidak menggagalkan registrasi, user bisa minta kirim ulang
+	if err := h.verificationService.SendVerification(user.ID); err != nil {
+		log.Println("send verification email error:", err)
+	}
+
 	return response.SuccessWithStatus[any](c, fiber.StatusCreated, "user registered successfully", nil)
 }
 
@@ -61,19 +107,109 @@ func (h *authHandler) LoginUser(c *fiber.Ctx) error {
 		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
 	}
 
+	meta := sessionMetadata(c)
+
+	retryAfter, err := h.loginThrottle.Check(req.Email, meta)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to login user")
+	}
+
+	// dikunci juga untuk email yang tidak terdaftar, jadi tidak membocorkan apa-apa
+	if retryAfter > 0 {
+		return loginLocked(c, retryAfter)
+	}
+
 	user, err := h.authService.LoginUser(&req)
 
 	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			if err := h.loginThrottle.RegisterFailure(req.Email, meta); err != nil {
+				log.Println("register login failure error:", err)
+			}
+			return response.Fail(c, fiber.StatusUnauthorized, ErrInvalidCredentials.Error())
+		}
 		return response.Fail(c, fiber.StatusInternalServerError, "failed to login user")
 	}
 
-	_, err = token.GenerateToken(c, user.ID)
+	twoFactorEnabled, err := h.twoFactorService.IsEnabled(user.ID)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to login user")
+	}
+
+	// token baru diterbitkan setelah kode 2FA diverifikasi di /login/2fa.
+	// Counter gagal baru di-reset di sana, password yang benar saja belum
+	// cukup untuk membuka kunci akun
+	if twoFactorEnabled {
+		challenge, err := h.twoFactorService.CreateChallenge(user.ID)
+		if err != nil {
+			return response.Fail(c, fiber.StatusInternalServerError, "failed to create login challenge")
+		}
+
+		return response.Success(c, "two-factor authentication required", challenge)
+	}
+
+	if err := h.loginThrottle.RegisterSuccess(req.Email); err != nil {
+		log.Println("reset login throttle error:", err)
+	}
+
+	session, err := h.sessionService.CreateSession(user.ID, meta)
+
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to create session")
+	}
 
+	tokens, err := issueTokens(c, h.tokens, session)
 	if err != nil {
 		return response.Fail(c, fiber.StatusInternalServerError, "failed to generate token")
 	}
 
-	return response.Success(c, "login successful", user)
+	return loginSuccess(c, user, tokens)
+}
+
+func (h *authHandler) RefreshToken(c *fiber.Ctx) error {
+	refreshToken := h.tokens.Cookies.RefreshTokenFromCookie(c)
+
+	if refreshToken == "" {
+		var req RefreshTokenRequest
+		if err := c.BodyParser(&req); err == nil {
+			refreshToken = req.RefreshToken
+		}
+	}
+
+	if refreshToken == "" {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: missing refresh token")
+	}
+
+	session, err := h.sessionService.RefreshSession(refreshToken, sessionMetadata(c))
+
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			log.Println("refresh token reuse detected, session revoked")
+		}
+
+		h.tokens.Cookies.ClearAuthTokens(c)
+
+		switch {
+		case errors.Is(err, ErrInvalidRefreshToken),
+			errors.Is(err, ErrRefreshTokenReused),
+			errors.Is(err, ErrSessionRevoked),
+			errors.Is(err, ErrSessionExpired):
+			return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: "+err.Error())
+		}
+
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to refresh session")
+	}
+
+	tokens, err := issueTokens(c, h.tokens, session)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to generate token")
+	}
+
+	if tokens != nil {
+		return response.Success(c, "token refreshed", tokens)
+	}
+
+	return response.Success[any](c, "token refreshed", nil)
 }
 
 func (h *authHandler) GetUser(c *fiber.Ctx) error {
@@ -98,17 +234,276 @@ func (h *authHandler) GetUser(c *fiber.Ctx) error {
 }
 
 func (h *authHandler) LogoutUser(c *fiber.Ctx) error {
-	c.Cookie(&fiber.Cookie{
-		Name:     "token",
-		Value:    "", // kosongkan
-		Path:     "/",
-		Expires:  time.Unix(0, 0), // waktu lampau
-		MaxAge:   -1,              // beberapa browser honor ini
-		HTTPOnly: true,
-		Secure:   false,
-		SameSite: fiber.CookieSameSiteLaxMode,
-		// Domain: "example.com", // hanya set kalau kamu set waktu create cookie
-	})
+	refreshToken := h.tokens.Cookies.RefreshTokenFromCookie(c)
+
+	// client mode token mengirim refresh token di body
+	if refreshToken == "" {
+		var req RefreshTokenRequest
+		if err := c.BodyParser(&req); err == nil {
+			refreshToken = req.RefreshToken
+		}
+	}
+
+	// Revoke session di server, bukan cuma hapus cookie
+	if refreshToken != "" {
+		if err := h.sessionService.RevokeByRefreshToken(refreshToken, SessionRevokedLogout); err != nil && !errors.Is(err, ErrInvalidRefreshToken) {
+			return response.Fail(c, fiber.StatusInternalServerError, "failed to revoke session")
+		}
+	} else if claims, err := h.tokens.Keys.ParseToken(h.tokens.Cookies.AccessTokenFromCookie(c)); err == nil {
+		if err := h.sessionService.RevokeSession(claims.SessionID, SessionRevokedLogout); err != nil {
+			return response.Fail(c, fiber.StatusInternalServerError, "failed to revoke session")
+		}
+	}
+
+	h.tokens.Cookies.ClearAuthTokens(c)
 
 	return response.Success[any](c, "logout successful", nil)
 }
+
+func (h *authHandler) GetSessions(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	sessions, err := h.sessionService.GetUserSessions(claims.UserID, claims.SessionID)
+
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to get sessions")
+	}
+
+	return response.Success(c, "sessions retrieved", sessions)
+}
+
+func (h *authHandler) RevokeSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil || sessionID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid session id")
+	}
+
+	if err := h.sessionService.RevokeUserSession(claims.UserID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return response.Fail(c, fiber.StatusNotFound, "session not found")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to revoke session")
+	}
+
+	// session yang sedang dipakai ikut di-revoke, sekalian hapus cookie-nya
+	if sessionID == claims.SessionID {
+		h.tokens.Cookies.ClearAuthTokens(c)
+	}
+
+	return response.SuccessNoData(c, "session revoked")
+}
+
+func (h *authHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	revoked, err := h.sessionService.RevokeOtherSessions(claims.UserID, claims.SessionID)
+
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to revoke sessions")
+	}
+
+	return response.Success(c, "other sessions revoked", RevokeSessionsResponse{Revoked: revoked})
+}
+
+func (h *authHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	if err := h.passwordResetService.ForgotPassword(&req); err != nil {
+		log.Println("forgot password error:", err)
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to process password reset request")
+	}
+
+	return response.SuccessNoData(c, "if the email is registered, a password reset link has been sent")
+}
+
+func (h *authHandler) ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	if err := h.passwordResetService.ResetPassword(&req); err != nil {
+		if errors.Is(err, ErrInvalidResetToken) {
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to reset password")
+	}
+
+	h.tokens.Cookies.ClearAuthTokens(c)
+
+	return response.SuccessNoData(c, "password has been reset, please login again")
+}
+
+func (h *authHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req VerifyEmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	if err := h.verificationService.VerifyEmail(&req); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidVerificationToken):
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrEmailTaken):
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to verify email")
+	}
+
+	return response.SuccessNoData(c, "email verified")
+}
+
+func (h *authHandler) ResendVerification(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	if err := h.verificationService.SendVerification(claims.UserID); err != nil {
+		switch {
+		case errors.Is(err, ErrEmailAlreadyVerified):
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case errors.Is(err, ErrVerificationThrottled):
+			return response.Fail(c, fiber.StatusTooManyRequests, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to send verification email")
+	}
+
+	return response.SuccessNoData(c, "verification email sent")
+}
+
+func loginLocked(c *fiber.Ctx, retryAfter time.Duration) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return response.Fail(c, fiber.StatusTooManyRequests, "too many failed login attempts, please try again later")
+}
+
+// wantsTokenResponse: client non-browser (mobile, server) tidak memakai
+// cookie, token dikirim di body lalu dipakai lewat Authorization: Bearer
+func wantsTokenResponse(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get("X-Auth-Mode"), "token")
+}
+
+// issueTokens mengembalikan token untuk body response kalau client meminta
+// mode token, kalau tidak token di-set sebagai cookie dan hasilnya nil
+func issueTokens(c *fiber.Ctx, tokens *token.Tokens, session *IssuedSession) (*TokenResponse, error) {
+	accessToken, err := tokens.Keys.SignAccessToken(
+		session.UserID,
+		session.SessionID,
+		string(session.Role),
+		permission.ForRole(session.Role),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if wantsTokenResponse(c) {
+		return &TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: session.RefreshToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(token.AccessTokenTTL.Seconds()),
+		}, nil
+	}
+
+	tokens.Cookies.SetAuthToken(c, accessToken)
+	tokens.Cookies.SetRefreshToken(c, session.RefreshToken, session.ExpiresAt)
+
+	return nil, nil
+}
+
+func loginSuccess(c *fiber.Ctx, user *AuthResponse, tokens *TokenResponse) error {
+	if tokens != nil {
+		return response.Success(c, "login successful", LoginTokenResponse{User: user, TokenResponse: *tokens})
+	}
+
+	return response.Success(c, "login successful", user)
+}
+
+// batas panjang metadata sesuai kolom sessions, header dari client bisa
+// sepanjang apa pun
+const (
+	maxDeviceLength    = 100
+	maxIPAddressLength = 64
+	maxUserAgentLength = 512
+)
+
+func sessionMetadata(c *fiber.Ctx) *SessionMetadata {
+	userAgent := c.Get(fiber.HeaderUserAgent)
+
+	device := c.Get("X-Device-Name")
+	if device == "" {
+		device = deviceFromUserAgent(userAgent)
+	}
+
+	return &SessionMetadata{
+		Device:    truncate(device, maxDeviceLength),
+		IPAddress: truncate(c.IP(), maxIPAddressLength),
+		UserAgent: truncate(userAgent, maxUserAgentLength),
+	}
+}
+
+// truncate memotong s menjadi paling banyak max karakter tanpa memotong
+// karakter multibyte di tengah
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	return string([]rune(s)[:max])
+}
+
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case ua == "":
+		return "unknown"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		return "iOS"
+	case strings.Contains(ua, "android"):
+		return "Android"
+	case strings.Contains(ua, "windows"):
+		return "Windows"
+	case strings.Contains(ua, "mac os"):
+		return "macOS"
+	case strings.Contains(ua, "linux"):
+		return "Linux"
+	default:
+		return "other"
+	}
+}
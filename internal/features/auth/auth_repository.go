@@ -1,14 +1,23 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
+	WithTx(tx *gorm.DB) UserRepository
 	RegisterUser(user *User) error
 	FindByEmail(email string) (*User, error)
 	FindByID(id uuid.UUID) (*User, error)
+	UpdatePassword(id uuid.UUID, hashedPassword string) error
+	MarkEmailVerified(id uuid.UUID, email string) (bool, error)
+	ClaimVerificationSend(id uuid.UUID, notBefore time.Time) (bool, error)
+	UpdateProfile(id uuid.UUID, updates map[string]interface{}) error
+	SetPendingEmail(id uuid.UUID, email string) error
+	ConfirmEmailChange(id uuid.UUID, email string) (bool, error)
 }
 
 type userRepository struct {
@@ -21,6 +30,10 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	}
 }
 
+func (r *userRepository) WithTx(tx *gorm.DB) UserRepository {
+	return &userRepository{db: tx}
+}
+
 func (r *userRepository) RegisterUser(user *User) error {
 	result := r.db.Create(user)
 	return result.Error
@@ -40,3 +53,87 @@ func (r *userRepository) FindByID(id uuid.UUID) (*User, error) {
 
 	return &user, result.Error
 }
+
+func (r *userRepository) UpdatePassword(id uuid.UUID, hashedPassword string) error {
+	result := r.db.
+		Model(&User{}).
+		Where("id = ?", id).
+		Update("password", hashedPassword)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified hanya berhasil kalau email user masih sama
+// dengan email yang ada di token verifikasi
+func (r *userRepository) MarkEmailVerified(id uuid.UUID, email string) (bool, error) {
+	result := r.db.
+		Model(&User{}).
+		Where("id = ? AND email = ?", id, email).
+		Update("email_verified_at", gorm.Expr("COALESCE(email_verified_at, ?)", time.Now().UTC()))
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimVerificationSend mencatat waktu kirim email verifikasi, mengembalikan
+// false kalau email terakhir dikirim setelah notBefore (throttle)
+func (r *userRepository) ClaimVerificationSend(id uuid.UUID, notBefore time.Time) (bool, error) {
+	result := r.db.
+		Model(&User{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at < ?)", id, notBefore).
+		Update("verification_sent_at", time.Now().UTC())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *userRepository) UpdateProfile(id uuid.UUID, updates map[string]interface{}) error {
+	return r.db.
+		Model(&User{}).
+		Where("id = ?", id).
+		Updates(updates).
+		Error
+}
+
+func (r *userRepository) SetPendingEmail(id uuid.UUID, email string) error {
+	return r.db.
+		Model(&User{}).
+		Where("id = ?", id).
+		Update("pending_email", email).
+		Error
+}
+
+// ConfirmEmailChange memindahkan pending_email ke email, hanya kalau
+// pending_email masih sama dengan email di token verifikasi
+func (r *userRepository) ConfirmEmailChange(id uuid.UUID, email string) (bool, error) {
+	updates := map[string]interface{}{
+		"email":             email,
+		"pending_email":     "",
+		"email_verified_at": time.Now().UTC(),
+	}
+
+	result := r.db.
+		Model(&User{}).
+		Where("id = ? AND pending_email = ?", id, email).
+		Updates(updates)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -1,13 +1,23 @@
 package auth
 
 import (
+	"errors"
+
 	util "go-fiber-api/internal/util/password"
+	"go-fiber-api/internal/util/permission"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// hash bcrypt dummy supaya login dengan email yang tidak terdaftar
+// butuh waktu yang sama dengan password salah
+const dummyPasswordHash = "$2a$10$xXaCSlECXS.tIkgPZMjZiOAN0ojF21N1R4UHB5ZTRw8nwX4516uP."
+
 type AuthService interface {
-	RegisterUser(req *RegisterUserRequest) error
+	RegisterUser(req *RegisterUserRequest) (*AuthResponse, error)
 	LoginUser(req *LoginRequest) (*AuthResponse, error)
 	GetUser(id uuid.UUID) (*AuthResponse, error)
 }
@@ -22,55 +32,64 @@ func NewAuthService(repo UserRepository) AuthService {
 	}
 }
 
-func (s *authService) RegisterUser(req *RegisterUserRequest) error {
+func toAuthResponse(user *User) *AuthResponse {
+	return &AuthResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		Role:             string(user.Role),
+		EmailVerified:    user.EmailVerifiedAt.Valid,
+		PendingEmail:     user.PendingEmail,
+		DisplayName:      user.DisplayName,
+		PhoneNumber:      user.PhoneNumber,
+		AvatarURL:        user.AvatarURL,
+		Locale:           user.Locale,
+		MarketingConsent: user.MarketingConsent,
+		CreatedAt:        user.CreatedAt.Time.String(),
+		UpdatedAt:        user.UpdatedAt.Time.String(),
+	}
+}
+
+func (s *authService) RegisterUser(req *RegisterUserRequest) (*AuthResponse, error) {
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	user := &User{
 		Email:    req.Email,
 		Password: hashedPassword,
+		Role:     permission.RoleCustomer,
+	}
+
+	if err := s.authRepo.RegisterUser(user); err != nil {
+		return nil, err
 	}
 
-	return s.authRepo.RegisterUser(user)
+	return toAuthResponse(user), nil
 }
 
 func (s *authService) LoginUser(req *LoginRequest) (*AuthResponse, error) {
 	user, err := s.authRepo.FindByEmail(req.Email)
 
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = util.CheckPassword(dummyPasswordHash, req.Password)
+			return nil, ErrInvalidCredentials
+		}
 		return nil, err
 	}
 
-	err = util.CheckPassword(user.Password, req.Password)
-
-	if err != nil {
-		return nil, err
-	}
-	result := &AuthResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Time.String(),
-		UpdatedAt: user.UpdatedAt.Time.String(),
+	if err := util.CheckPassword(user.Password, req.Password); err != nil {
+		return nil, ErrInvalidCredentials
 	}
-
-	return result, nil
+	return toAuthResponse(user), nil
 }
 
 func (s *authService) GetUser(id uuid.UUID) (*AuthResponse, error) {
 	user, err := s.authRepo.FindByID(id)
-
-	result := &AuthResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Time.String(),
-		UpdatedAt: user.UpdatedAt.Time.String(),
-	}
-
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return toAuthResponse(user), nil
 }
@@ -0,0 +1,5 @@
+package auth
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/util/mailer"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// jeda minimal antar pengiriman ulang email verifikasi
+const verificationResendInterval = time.Minute
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification link")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationThrottled    = errors.New("verification email was sent recently, please try again later")
+	ErrEmailTaken               = errors.New("email is already used by another account")
+)
+
+type EmailVerificationService interface {
+	SendVerification(userID uuid.UUID) error
+	VerifyEmail(req *VerifyEmailRequest) error
+	IsEmailVerified(userID uuid.UUID) (bool, error)
+}
+
+type emailVerificationService struct {
+	userRepo   UserRepository
+	mailer     mailer.Mailer
+	keys       *token.KeySet
+	appBaseURL string
+}
+
+func NewEmailVerificationService(userRepo UserRepository, mail mailer.Mailer, keys *token.KeySet, appBaseURL string) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:   userRepo,
+		mailer:     mail,
+		keys:       keys,
+		appBaseURL: strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+func (s *emailVerificationService) SendVerification(userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		return ErrEmailAlreadyVerified
+	}
+
+	claimed, err := s.userRepo.ClaimVerificationSend(user.ID, time.Now().UTC().Add(-verificationResendInterval))
+	if err != nil {
+		return err
+	}
+
+	if !claimed {
+		return ErrVerificationThrottled
+	}
+
+	return sendVerificationLink(s.mailer, s.keys, s.appBaseURL, user.ID, user.Email)
+}
+
+// sendVerificationLink juga dipakai saat user mengganti email,
+// link dikirim ke alamat yang akan diverifikasi
+func sendVerificationLink(m mailer.Mailer, keys *token.KeySet, appBaseURL string, userID uuid.UUID, email string) error {
+	verificationToken, err := keys.GenerateEmailVerificationToken(userID, email)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", appBaseURL, url.QueryEscape(verificationToken))
+	mailer.SendAsync(m, mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Open this link within %d hours to verify your email address:\n%s\n\nIf you did not request this, you can ignore this email.",
+			int(token.EmailVerificationTTL.Hours()),
+			link,
+		),
+	})
+
+	return nil
+}
+
+func (s *emailVerificationService) VerifyEmail(req *VerifyEmailRequest) error {
+	claims, err := s.keys.ParseEmailVerificationToken(req.Token)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	verified, err := s.userRepo.MarkEmailVerified(claims.UserID, claims.Email)
+	if err != nil {
+		return err
+	}
+
+	if verified {
+		return nil
+	}
+
+	// token untuk alamat baru dari fitur ganti email
+	existing, err := s.userRepo.FindByEmail(claims.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && existing.ID != claims.UserID {
+		return ErrEmailTaken
+	}
+
+	changed, err := s.userRepo.ConfirmEmailChange(claims.UserID, claims.Email)
+	if err != nil {
+		return err
+	}
+
+	// email sudah diganti lagi atau user sudah dihapus
+	if !changed {
+		return ErrInvalidVerificationToken
+	}
+
+	return nil
+}
+
+func (s *emailVerificationService) IsEmailVerified(userID uuid.UUID) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return user.EmailVerifiedAt.Valid, nil
+}
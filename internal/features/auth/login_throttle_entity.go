@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ThrottleScopeAccount = "ACCOUNT"
+	ThrottleScopeIP      = "IP"
+)
+
+// LoginThrottle menghitung gagal login berturut-turut per email atau per IP
+type LoginThrottle struct {
+	Scope string `gorm:"type:varchar(20);primaryKey"`
+	Key   string `gorm:"type:varchar(255);primaryKey"`
+
+	Failures     int       `gorm:"not null;default:0"`
+	LastFailedAt time.Time `gorm:"not null"`
+	LockedUntil  *time.Time
+}
+
+const (
+	AuditEventAccountLocked  = "ACCOUNT_LOCKED"
+	AuditEventIPLocked       = "IP_LOCKED"
+	AuditEventAccountDeleted = "ACCOUNT_DELETED"
+)
+
+type AuditLog struct {
+	ID     uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID *uuid.UUID `gorm:"type:uuid;index"`
+	Event  string     `gorm:"type:varchar(50);not null;index"`
+
+	Email     string `gorm:"type:varchar(255)"`
+	IPAddress string `gorm:"type:varchar(64)"`
+	UserAgent string `gorm:"type:text"`
+	Detail    string `gorm:"type:text"`
+
+	CreatedAt time.Time `gorm:"index"`
+}
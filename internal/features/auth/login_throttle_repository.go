@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginThrottleRepository interface {
+	FindLocked(scope string, key string, now time.Time) (*LoginThrottle, error)
+	RegisterFailure(scope string, key string, resetBefore time.Time) (*LoginThrottle, error)
+	Lock(scope string, key string, until time.Time) error
+	Reset(scope string, key string) error
+	CreateAuditLog(entry *AuditLog) error
+}
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{
+		db: db,
+	}
+}
+
+// FindLocked mengembalikan nil tanpa error kalau key tidak sedang dikunci
+func (r *loginThrottleRepository) FindLocked(scope string, key string, now time.Time) (*LoginThrottle, error) {
+	var throttle LoginThrottle
+
+	err := r.db.
+		Where("scope = ? AND key = ? AND locked_until > ?", scope, key, now).
+		Take(&throttle).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttle, nil
+}
+
+// RegisterFailure menambah counter secara atomik. Counter dimulai dari awal
+// kalau gagal terakhir terjadi sebelum resetBefore
+func (r *loginThrottleRepository) RegisterFailure(scope string, key string, resetBefore time.Time) (*LoginThrottle, error) {
+	var throttle LoginThrottle
+	now := time.Now().UTC()
+
+	err := r.db.Raw(`
+		INSERT INTO login_throttles (scope, key, failures, last_failed_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE
+				WHEN login_throttles.last_failed_at < ? THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING scope, key, failures, last_failed_at, locked_until
+	`, scope, key, now, resetBefore).Scan(&throttle).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttle, nil
+}
+
+func (r *loginThrottleRepository) Lock(scope string, key string, until time.Time) error {
+	return r.db.
+		Model(&LoginThrottle{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Update("locked_until", until).
+		Error
+}
+
+func (r *loginThrottleRepository) Reset(scope string, key string) error {
+	return r.db.
+		Where("scope = ? AND key = ?", scope, key).
+		Delete(&LoginThrottle{}).
+		Error
+}
+
+func (r *loginThrottleRepository) CreateAuditLog(entry *AuditLog) error {
+	return r.db.Create(entry).Error
+}
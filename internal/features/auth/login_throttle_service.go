@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type throttlePolicy struct {
+	// jumlah gagal sebelum mulai dikunci
+	threshold int
+	// lama kunci pertama, dikali dua setiap gagal berikutnya
+	baseLockout time.Duration
+	maxLockout  time.Duration
+	// counter dianggap hangus kalau tidak ada gagal selama window ini
+	window time.Duration
+}
+
+var (
+	accountThrottlePolicy = throttlePolicy{
+		threshold:   5,
+		baseLockout: time.Minute,
+		maxLockout:  time.Hour,
+		window:      time.Hour,
+	}
+
+	// satu IP bisa dipakai banyak user (NAT, kantor), jadi batasnya lebih longgar
+	ipThrottlePolicy = throttlePolicy{
+		threshold:   20,
+		baseLockout: time.Minute,
+		maxLockout:  time.Hour,
+		window:      time.Hour,
+	}
+)
+
+type LoginThrottleService interface {
+	// Check mengembalikan sisa waktu kunci, nol kalau boleh mencoba login
+	Check(email string, meta *SessionMetadata) (time.Duration, error)
+	RegisterFailure(email string, meta *SessionMetadata) error
+	RegisterSuccess(email string) error
+}
+
+type loginThrottleService struct {
+	throttleRepo LoginThrottleRepository
+	userRepo     UserRepository
+}
+
+func NewLoginThrottleService(throttleRepo LoginThrottleRepository, userRepo UserRepository) LoginThrottleService {
+	return &loginThrottleService{
+		throttleRepo: throttleRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (s *loginThrottleService) Check(email string, meta *SessionMetadata) (time.Duration, error) {
+	now := time.Now().UTC()
+	var retryAfter time.Duration
+
+	keys := map[string]string{
+		ThrottleScopeAccount: normalizeLoginEmail(email),
+		ThrottleScopeIP:      meta.IPAddress,
+	}
+
+	for scope, key := range keys {
+		throttle, err := s.throttleRepo.FindLocked(scope, key, now)
+		if err != nil {
+			return 0, err
+		}
+
+		if throttle != nil && throttle.LockedUntil.Sub(now) > retryAfter {
+			retryAfter = throttle.LockedUntil.Sub(now)
+		}
+	}
+
+	return retryAfter, nil
+}
+
+func (s *loginThrottleService) RegisterFailure(email string, meta *SessionMetadata) error {
+	email = normalizeLoginEmail(email)
+
+	if err := s.registerFailure(ThrottleScopeAccount, email, accountThrottlePolicy, AuditEventAccountLocked, email, meta); err != nil {
+		return err
+	}
+
+	return s.registerFailure(ThrottleScopeIP, meta.IPAddress, ipThrottlePolicy, AuditEventIPLocked, email, meta)
+}
+
+func (s *loginThrottleService) RegisterSuccess(email string) error {
+	// counter per IP sengaja tidak di-reset, supaya penyerang tidak bisa
+	// menghapusnya dengan login ke akun miliknya sendiri
+	return s.throttleRepo.Reset(ThrottleScopeAccount, normalizeLoginEmail(email))
+}
+
+func (s *loginThrottleService) registerFailure(
+	scope string,
+	key string,
+	policy throttlePolicy,
+	event string,
+	email string,
+	meta *SessionMetadata,
+) error {
+	now := time.Now().UTC()
+
+	throttle, err := s.throttleRepo.RegisterFailure(scope, key, now.Add(-policy.window))
+	if err != nil {
+		return err
+	}
+
+	if throttle.Failures < policy.threshold {
+		return nil
+	}
+
+	lockout := policy.lockoutFor(throttle.Failures)
+	lockedUntil := now.Add(lockout)
+
+	if err := s.throttleRepo.Lock(scope, key, lockedUntil); err != nil {
+		return err
+	}
+
+	entry := &AuditLog{
+		Event:     event,
+		Email:     email,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Detail:    fmt.Sprintf("%d consecutive failed logins, locked for %s", throttle.Failures, lockout),
+	}
+
+	if scope == ThrottleScopeAccount {
+		user, err := s.userRepo.FindByEmail(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			entry.UserID = &user.ID
+		}
+	}
+
+	if err := s.throttleRepo.CreateAuditLog(entry); err != nil {
+		return err
+	}
+
+	log.Printf("login lockout: scope=%s email=%s ip=%s until=%s", scope, email, meta.IPAddress, lockedUntil.Format(time.RFC3339))
+
+	return nil
+}
+
+// lockoutFor menggandakan lama kunci untuk setiap gagal setelah threshold
+func (p throttlePolicy) lockoutFor(failures int) time.Duration {
+	lockout := p.baseLockout
+
+	for i := p.threshold; i < failures; i++ {
+		lockout *= 2
+		if lockout >= p.maxLockout {
+			return p.maxLockout
+		}
+	}
+
+	return lockout
+}
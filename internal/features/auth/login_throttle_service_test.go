@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type memoryThrottleRepository struct {
+	throttles map[string]*LoginThrottle
+	audits    []*AuditLog
+}
+
+func newMemoryThrottleRepository() *memoryThrottleRepository {
+	return &memoryThrottleRepository{throttles: map[string]*LoginThrottle{}}
+}
+
+func (r *memoryThrottleRepository) FindLocked(scope string, key string, now time.Time) (*LoginThrottle, error) {
+	throttle, ok := r.throttles[scope+"|"+key]
+	if !ok || throttle.LockedUntil == nil || !throttle.LockedUntil.After(now) {
+		return nil, nil
+	}
+	return throttle, nil
+}
+
+func (r *memoryThrottleRepository) RegisterFailure(scope string, key string, resetBefore time.Time) (*LoginThrottle, error) {
+	throttle, ok := r.throttles[scope+"|"+key]
+	if !ok {
+		throttle = &LoginThrottle{Scope: scope, Key: key}
+		r.throttles[scope+"|"+key] = throttle
+	}
+
+	if throttle.LastFailedAt.Before(resetBefore) {
+		throttle.Failures = 0
+	}
+
+	throttle.Failures++
+	throttle.LastFailedAt = time.Now().UTC()
+
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *memoryThrottleRepository) Lock(scope string, key string, until time.Time) error {
+	r.throttles[scope+"|"+key].LockedUntil = &until
+	return nil
+}
+
+func (r *memoryThrottleRepository) Reset(scope string, key string) error {
+	delete(r.throttles, scope+"|"+key)
+	return nil
+}
+
+func (r *memoryThrottleRepository) CreateAuditLog(entry *AuditLog) error {
+	r.audits = append(r.audits, entry)
+	return nil
+}
+
+type memoryUserRepository struct {
+	UserRepository
+	users map[string]*User
+}
+
+func (r *memoryUserRepository) FindByEmail(email string) (*User, error) {
+	user, ok := r.users[email]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func TestLockoutFor(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 5, want: time.Minute},
+		{failures: 6, want: 2 * time.Minute},
+		{failures: 8, want: 8 * time.Minute},
+		{failures: 11, want: time.Hour},
+		{failures: 50, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := accountThrottlePolicy.lockoutFor(tt.failures); got != tt.want {
+			t.Errorf("lockoutFor(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestAccountLockoutAndReset(t *testing.T) {
+	user := &User{ID: uuid.New(), Email: "user@example.com"}
+	repo := newMemoryThrottleRepository()
+	service := NewLoginThrottleService(repo, &memoryUserRepository{users: map[string]*User{user.Email: user}})
+	meta := &SessionMetadata{IPAddress: "10.0.0.1"}
+
+	for i := 1; i < accountThrottlePolicy.threshold; i++ {
+		if err := service.RegisterFailure(user.Email, meta); err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+	}
+
+	if retryAfter, _ := service.Check(user.Email, meta); retryAfter != 0 {
+		t.Fatalf("locked after %d failures, want unlocked", accountThrottlePolicy.threshold-1)
+	}
+
+	// email dinormalisasi, jadi variasi huruf besar tetap dihitung akun yang sama
+	if err := service.RegisterFailure("  USER@example.com ", meta); err != nil {
+		t.Fatalf("RegisterFailure: %v", err)
+	}
+
+	retryAfter, err := service.Check(user.Email, &SessionMetadata{IPAddress: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	if retryAfter <= 0 || retryAfter > accountThrottlePolicy.baseLockout {
+		t.Fatalf("retry after = %s, want up to %s", retryAfter, accountThrottlePolicy.baseLockout)
+	}
+
+	if len(repo.audits) != 1 || repo.audits[0].Event != AuditEventAccountLocked || repo.audits[0].UserID == nil || *repo.audits[0].UserID != user.ID {
+		t.Fatalf("audit logs = %+v, want one ACCOUNT_LOCKED entry for the user", repo.audits)
+	}
+
+	if err := service.RegisterSuccess(user.Email); err != nil {
+		t.Fatalf("RegisterSuccess: %v", err)
+	}
+
+	if retryAfter, _ := service.Check(user.Email, meta); retryAfter != 0 {
+		t.Fatalf("retry after reset = %s, want 0", retryAfter)
+	}
+
+	// counter per IP tidak ikut di-reset oleh login yang berhasil
+	if ip := repo.throttles[ThrottleScopeIP+"|"+meta.IPAddress]; ip == nil || ip.Failures != accountThrottlePolicy.threshold {
+		t.Fatalf("ip throttle = %+v, want %d failures", ip, accountThrottlePolicy.threshold)
+	}
+}
+
+func TestIPLockoutAcrossAccounts(t *testing.T) {
+	repo := newMemoryThrottleRepository()
+	service := NewLoginThrottleService(repo, &memoryUserRepository{})
+	meta := &SessionMetadata{IPAddress: "10.0.0.1"}
+
+	for i := 0; i < ipThrottlePolicy.threshold; i++ {
+		if err := service.RegisterFailure(uuid.NewString()+"@example.com", meta); err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+	}
+
+	if retryAfter, _ := service.Check("other@example.com", meta); retryAfter <= 0 {
+		t.Fatal("ip not locked after reaching the threshold")
+	}
+
+	if retryAfter, _ := service.Check("other@example.com", &SessionMetadata{IPAddress: "10.0.0.2"}); retryAfter != 0 {
+		t.Fatalf("other ip retry after = %s, want 0", retryAfter)
+	}
+
+	last := repo.audits[len(repo.audits)-1]
+	if last.Event != AuditEventIPLocked || last.UserID != nil {
+		t.Fatalf("last audit = %+v, want IP_LOCKED without user", last)
+	}
+}
+
+func TestFailureCounterExpiresAfterWindow(t *testing.T) {
+	repo := newMemoryThrottleRepository()
+	service := NewLoginThrottleService(repo, &memoryUserRepository{})
+	meta := &SessionMetadata{IPAddress: "10.0.0.1"}
+
+	for i := 1; i < accountThrottlePolicy.threshold; i++ {
+		if err := service.RegisterFailure("user@example.com", meta); err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+	}
+
+	// gagal terakhir sudah lewat window, counter mulai dari awal
+	repo.throttles[ThrottleScopeAccount+"|user@example.com"].LastFailedAt = time.Now().Add(-accountThrottlePolicy.window - time.Minute)
+
+	if err := service.RegisterFailure("user@example.com", meta); err != nil {
+		t.Fatalf("RegisterFailure: %v", err)
+	}
+
+	if retryAfter, _ := service.Check("user@example.com", meta); retryAfter != 0 {
+		t.Fatalf("retry after = %s, want 0 after the window reset", retryAfter)
+	}
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity menghubungkan user dengan akun di provider OIDC (sub)
+type UserIdentity struct {
+	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Provider string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identity_subject"`
+	Subject  string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identity_subject"`
+	Email    string    `gorm:"type:varchar(255)"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OIDCAuthRequest menyimpan state, nonce dan PKCE verifier
+// selama user berada di halaman login provider
+type OIDCAuthRequest struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Provider     string    `gorm:"type:varchar(50);not null"`
+	StateHash    string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Nonce        string    `gorm:"type:varchar(100);not null"`
+	CodeVerifier string    `gorm:"type:varchar(100);not null"`
+
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
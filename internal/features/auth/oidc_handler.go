@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	oidcStateCookieName = "oidc_state"
+	oidcCookiePath      = "/api/auth/oidc"
+)
+
+type OIDCHandler interface {
+	Login(c *fiber.Ctx) error
+	Callback(c *fiber.Ctx) error
+}
+
+type oidcHandler struct {
+	oidcService      OIDCService
+	sessionService   SessionService
+	twoFactorService TwoFactorService
+	tokens           *token.Tokens
+	appBaseURL       string
+}
+
+func NewOIDCHandler(
+	oidcService OIDCService,
+	sessionService SessionService,
+	twoFactorService TwoFactorService,
+	tokens *token.Tokens,
+	appBaseURL string,
+) OIDCHandler {
+	return &oidcHandler{
+		oidcService:      oidcService,
+		sessionService:   sessionService,
+		twoFactorService: twoFactorService,
+		tokens:           tokens,
+		appBaseURL:       strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+func (h *oidcHandler) Login(c *fiber.Ctx) error {
+	start, err := h.oidcService.BeginLogin(c.UserContext(), c.Params("provider"))
+	if err != nil {
+		if errors.Is(err, ErrOIDCProviderNotFound) {
+			return response.Fail(c, fiber.StatusNotFound, err.Error())
+		}
+		log.Println("oidc begin login error:", err)
+		return response.Fail(c, fiber.StatusBadGateway, "failed to start login with provider")
+	}
+
+	// state juga disimpan di cookie supaya callback terikat ke browser
+	// yang memulai login (mencegah login CSRF)
+	c.Cookie(h.oidcStateCookie(start.State, oidcAuthRequestTTL))
+
+	return c.Redirect(start.RedirectURL, fiber.StatusFound)
+}
+
+// Callback selalu redirect ke frontend, hasil gagal dikirim lewat query error
+func (h *oidcHandler) Callback(c *fiber.Ctx) error {
+	state := c.Query("state")
+	cookieState := c.Cookies(oidcStateCookieName)
+
+	c.Cookie(h.oidcStateCookie("", 0))
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		return h.redirectError(c, "provider_denied")
+	}
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		return h.redirectError(c, "invalid_state")
+	}
+
+	userID, err := h.oidcService.CompleteLogin(c.UserContext(), c.Params("provider"), state, c.Query("code"))
+	if err != nil {
+		log.Println("oidc callback error:", err)
+
+		switch {
+		case errors.Is(err, ErrOIDCInvalidState), errors.Is(err, ErrOIDCProviderNotFound):
+			return h.redirectError(c, "invalid_state")
+		case errors.Is(err, ErrOIDCEmailNotVerified):
+			return h.redirectError(c, "email_not_verified")
+		case errors.Is(err, ErrOIDCAccountNotLinkable):
+			return h.redirectError(c, "account_not_linkable")
+		}
+		return h.redirectError(c, "login_failed")
+	}
+
+	twoFactorEnabled, err := h.twoFactorService.IsEnabled(userID)
+	if err != nil {
+		return h.redirectError(c, "login_failed")
+	}
+
+	// login provider tidak menggantikan 2FA yang sudah diaktifkan user
+	if twoFactorEnabled {
+		challenge, err := h.twoFactorService.CreateChallenge(userID)
+		if err != nil {
+			return h.redirectError(c, "login_failed")
+		}
+
+		return c.Redirect(h.appBaseURL+"/login/2fa?challenge_token="+url.QueryEscape(challenge.ChallengeToken), fiber.StatusFound)
+	}
+
+	session, err := h.sessionService.CreateSession(userID, sessionMetadata(c))
+	if err != nil {
+		return h.redirectError(c, "login_failed")
+	}
+
+	// callback selalu dibuka browser, token dikirim lewat cookie
+	if _, err := issueTokens(c, h.tokens, session); err != nil {
+		return h.redirectError(c, "login_failed")
+	}
+
+	return c.Redirect(h.appBaseURL+"/", fiber.StatusFound)
+}
+
+func (h *oidcHandler) redirectError(c *fiber.Ctx, code string) error {
+	return c.Redirect(h.appBaseURL+"/login?error="+url.QueryEscape(code), fiber.StatusFound)
+}
+
+// oidcStateCookie selalu SameSite=Lax karena callback datang dari redirect
+// lintas situs milik provider, cookie Strict tidak akan ikut terkirim
+func (h *oidcHandler) oidcStateCookie(state string, ttl time.Duration) *fiber.Cookie {
+	cookie := h.tokens.Cookies.New(oidcStateCookieName, state, oidcCookiePath, ttl, true)
+	cookie.SameSite = fiber.CookieSameSiteLaxMode
+
+	return cookie
+}
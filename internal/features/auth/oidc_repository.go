@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OIDCRepository interface {
+	WithTx(tx *gorm.DB) OIDCRepository
+	CreateAuthRequest(authRequest *OIDCAuthRequest) error
+	ConsumeAuthRequest(stateHash string) (*OIDCAuthRequest, error)
+	FindIdentity(provider string, subject string) (*UserIdentity, error)
+	CreateIdentity(identity *UserIdentity) error
+}
+
+type oidcRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) OIDCRepository {
+	return &oidcRepository{
+		db: db,
+	}
+}
+
+func (r *oidcRepository) WithTx(tx *gorm.DB) OIDCRepository {
+	return &oidcRepository{db: tx}
+}
+
+func (r *oidcRepository) CreateAuthRequest(authRequest *OIDCAuthRequest) error {
+	return r.db.Create(authRequest).Error
+}
+
+// ConsumeAuthRequest menghapus sekaligus mengembalikan request,
+// jadi satu state hanya bisa dipakai sekali
+func (r *oidcRepository) ConsumeAuthRequest(stateHash string) (*OIDCAuthRequest, error) {
+	var authRequests []OIDCAuthRequest
+
+	result := r.db.
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&authRequests)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(authRequests) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &authRequests[0], nil
+}
+
+// FindIdentity mengembalikan nil tanpa error kalau identity belum terhubung
+func (r *oidcRepository) FindIdentity(provider string, subject string) (*UserIdentity, error) {
+	var identity UserIdentity
+
+	err := r.db.
+		Where("provider = ? AND subject = ?", provider, subject).
+		Take(&identity).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (r *oidcRepository) CreateIdentity(identity *UserIdentity) error {
+	return r.db.Create(identity).Error
+}
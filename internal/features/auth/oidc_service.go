@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/config"
+	"go-fiber-api/internal/util/oidc"
+	"go-fiber-api/internal/util/permission"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const oidcAuthRequestTTL = 10 * time.Minute
+
+var (
+	ErrOIDCProviderNotFound = errors.New("unknown login provider")
+	ErrOIDCInvalidState     = errors.New("invalid or expired login state")
+	ErrOIDCEmailNotVerified = errors.New("provider did not return a verified email")
+	// ErrOIDCAccountNotLinkable: akun lokal dengan email yang sama belum
+	// diverifikasi, jadi belum terbukti milik orang yang sama
+	ErrOIDCAccountNotLinkable = errors.New("an unverified account with this email already exists")
+)
+
+type OIDCLoginStart struct {
+	RedirectURL string
+	State       string
+}
+
+type OIDCService interface {
+	BeginLogin(ctx context.Context, provider string) (*OIDCLoginStart, error)
+	CompleteLogin(ctx context.Context, provider string, state string, code string) (uuid.UUID, error)
+}
+
+type oidcService struct {
+	db        *gorm.DB
+	userRepo  UserRepository
+	oidcRepo  OIDCRepository
+	providers map[string]*oidc.Provider
+}
+
+func NewOIDCService(
+	db *gorm.DB,
+	userRepo UserRepository,
+	oidcRepo OIDCRepository,
+	providers []config.OIDCProviderConfig,
+	apiBaseURL string,
+) OIDCService {
+	registered := make(map[string]*oidc.Provider, len(providers))
+
+	for _, provider := range providers {
+		registered[provider.Name] = oidc.NewProvider(oidc.Config{
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  strings.TrimRight(apiBaseURL, "/") + "/api/auth/oidc/" + provider.Name + "/callback",
+			Scopes:       provider.Scopes,
+		})
+	}
+
+	return &oidcService{
+		db:        db,
+		userRepo:  userRepo,
+		oidcRepo:  oidcRepo,
+		providers: registered,
+	}
+}
+
+func (s *oidcService) BeginLogin(ctx context.Context, providerName string) (*OIDCLoginStart, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.oidcRepo.CreateAuthRequest(&OIDCAuthRequest{
+		Provider:     providerName,
+		StateHash:    token.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().UTC().Add(oidcAuthRequestTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &OIDCLoginStart{
+		RedirectURL: redirectURL,
+		State:       state,
+	}, nil
+}
+
+// CompleteLogin menukar authorization code, memverifikasi id token lalu
+// mengembalikan user yang terhubung (atau baru dibuat) dengan identity tersebut
+func (s *oidcService) CompleteLogin(ctx context.Context, providerName string, state string, code string) (uuid.UUID, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return uuid.Nil, ErrOIDCProviderNotFound
+	}
+
+	authRequest, err := s.oidcRepo.ConsumeAuthRequest(token.HashToken(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrOIDCInvalidState
+		}
+		return uuid.Nil, err
+	}
+
+	if authRequest.Provider != providerName || time.Now().UTC().After(authRequest.ExpiresAt) {
+		return uuid.Nil, ErrOIDCInvalidState
+	}
+
+	tokens, err := provider.Exchange(ctx, code, authRequest.CodeVerifier)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, authRequest.Nonce)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return s.linkUser(providerName, claims)
+}
+
+func (s *oidcService) linkUser(providerName string, claims *oidc.IDTokenClaims) (uuid.UUID, error) {
+	identity, err := s.oidcRepo.FindIdentity(providerName, claims.Subject)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if identity != nil {
+		return identity.UserID, nil
+	}
+
+	// akun lokal hanya boleh dihubungkan kalau provider menjamin email-nya
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return uuid.Nil, ErrOIDCEmailNotVerified
+	}
+
+	var userID uuid.UUID
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		user, err := userRepo.FindByEmail(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err == nil && !user.EmailVerifiedAt.Valid {
+			// siapa pun bisa mendaftar dengan email orang lain lalu menunggu
+			// pemiliknya login lewat provider, jadi akun seperti ini tidak
+			// dihubungkan otomatis
+			return ErrOIDCAccountNotLinkable
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// user baru tidak punya password, login hanya lewat provider
+			// sampai user memakai fitur reset password
+			user = &User{
+				Email: email,
+				Role:  permission.RoleCustomer,
+			}
+
+			if err := userRepo.RegisterUser(user); err != nil {
+				return err
+			}
+
+			if _, err := userRepo.MarkEmailVerified(user.ID, user.Email); err != nil {
+				return err
+			}
+		}
+
+		userID = user.ID
+
+		return s.oidcRepo.WithTx(tx).CreateIdentity(&UserIdentity{
+			UserID:   user.ID,
+			Provider: providerName,
+			Subject:  claims.Subject,
+			Email:    email,
+		})
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
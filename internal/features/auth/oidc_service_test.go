@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"go-fiber-api/internal/config"
+	"go-fiber-api/internal/util/oidc/oidctest"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB butuh database postgres kosong di TEST_DATABASE_URL,
+// test dilewati kalau tidak diset
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect database: %v", err)
+	}
+
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+		t.Fatalf("create uuid extension: %v", err)
+	}
+
+	if err := db.AutoMigrate(&User{}, &UserIdentity{}, &OIDCAuthRequest{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return db
+}
+
+type oidcFixture struct {
+	db       *gorm.DB
+	mock     *oidctest.Provider
+	service  OIDCService
+	userRepo UserRepository
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+
+	db := openTestDB(t)
+	mock := oidctest.NewProvider(t, "client-id", "client-secret")
+	userRepo := NewUserRepository(db)
+
+	service := NewOIDCService(db, userRepo, NewOIDCRepository(db), []config.OIDCProviderConfig{{
+		Name:         "mock",
+		Issuer:       mock.Issuer(),
+		ClientID:     mock.ClientID,
+		ClientSecret: mock.ClientSecret,
+	}}, "http://localhost")
+
+	return &oidcFixture{db: db, mock: mock, service: service, userRepo: userRepo}
+}
+
+// login menjalankan alur lengkap: BeginLogin, provider mengeluarkan code
+// untuk challenge dan nonce dari redirect URL, lalu CompleteLogin
+func (f *oidcFixture) login(t *testing.T, subject, email string, emailVerified bool) (uuid.UUID, error) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	start, err := f.service.BeginLogin(ctx, "mock")
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+
+	redirect, err := url.Parse(start.RedirectURL)
+	if err != nil {
+		t.Fatalf("parse redirect url: %v", err)
+	}
+
+	claims := f.mock.Claims(subject, email, redirect.Query().Get("nonce"))
+	claims["email_verified"] = emailVerified
+
+	code := f.mock.IssueCode(redirect.Query().Get("code_challenge"), claims)
+
+	return f.service.CompleteLogin(ctx, "mock", start.State, code)
+}
+
+func (f *oidcFixture) createUser(t *testing.T, email string, verified bool) *User {
+	t.Helper()
+
+	user := &User{Email: email, Password: "hashed"}
+	if verified {
+		user.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	if err := f.db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	return user
+}
+
+func (f *oidcFixture) identityCount(t *testing.T, subject string) int64 {
+	t.Helper()
+
+	var count int64
+	if err := f.db.Model(&UserIdentity{}).Where("provider = ? AND subject = ?", "mock", subject).Count(&count).Error; err != nil {
+		t.Fatalf("count identities: %v", err)
+	}
+
+	return count
+}
+
+func uniqueEmail() string {
+	return uuid.NewString() + "@example.com"
+}
+
+func TestOIDCLoginCreatesVerifiedUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	subject, email := uuid.NewString(), uniqueEmail()
+
+	userID, err := f.login(t, subject, email, true)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	user, err := f.userRepo.FindByEmail(email)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+
+	if user.ID != userID || !user.EmailVerifiedAt.Valid {
+		t.Fatalf("user = %s verified=%v, want %s verified", user.ID, user.EmailVerifiedAt.Valid, userID)
+	}
+
+	// login berikutnya memakai identity yang sama
+	again, err := f.login(t, subject, email, true)
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+
+	if again != userID {
+		t.Fatalf("second login user = %s, want %s", again, userID)
+	}
+}
+
+func TestOIDCLoginLinksVerifiedAccount(t *testing.T) {
+	f := newOIDCFixture(t)
+	existing := f.createUser(t, uniqueEmail(), true)
+	subject := uuid.NewString()
+
+	userID, err := f.login(t, subject, existing.Email, true)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	if userID != existing.ID {
+		t.Fatalf("login user = %s, want existing user %s", userID, existing.ID)
+	}
+
+	if count := f.identityCount(t, subject); count != 1 {
+		t.Fatalf("identities = %d, want 1", count)
+	}
+}
+
+func TestOIDCLoginRefusesUnverifiedAccount(t *testing.T) {
+	f := newOIDCFixture(t)
+	existing := f.createUser(t, uniqueEmail(), false)
+	subject := uuid.NewString()
+
+	if _, err := f.login(t, subject, existing.Email, true); !errors.Is(err, ErrOIDCAccountNotLinkable) {
+		t.Fatalf("login error = %v, want %v", err, ErrOIDCAccountNotLinkable)
+	}
+
+	if count := f.identityCount(t, subject); count != 0 {
+		t.Fatalf("identities = %d, want 0", count)
+	}
+
+	user, err := f.userRepo.FindByEmail(existing.Email)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		t.Fatal("unverified account was marked verified by the provider login")
+	}
+}
+
+func TestOIDCLoginRequiresVerifiedProviderEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	if _, err := f.login(t, uuid.NewString(), uniqueEmail(), false); !errors.Is(err, ErrOIDCEmailNotVerified) {
+		t.Fatalf("login error = %v, want %v", err, ErrOIDCEmailNotVerified)
+	}
+}
+
+func TestOIDCLoginStateIsSingleUse(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	start, err := f.service.BeginLogin(ctx, "mock")
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+
+	redirect, err := url.Parse(start.RedirectURL)
+	if err != nil {
+		t.Fatalf("parse redirect url: %v", err)
+	}
+
+	claims := f.mock.Claims(uuid.NewString(), uniqueEmail(), redirect.Query().Get("nonce"))
+	challenge := redirect.Query().Get("code_challenge")
+
+	if _, err := f.service.CompleteLogin(ctx, "mock", start.State, f.mock.IssueCode(challenge, claims)); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+
+	if _, err := f.service.CompleteLogin(ctx, "mock", start.State, f.mock.IssueCode(challenge, claims)); !errors.Is(err, ErrOIDCInvalidState) {
+		t.Fatalf("reused state error = %v, want %v", err, ErrOIDCInvalidState)
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	WithTx(tx *gorm.DB) PasswordResetRepository
+	Create(resetToken *PasswordResetToken) error
+	FindByHash(hash string) (*PasswordResetToken, error)
+	MarkUsed(id uuid.UUID) (bool, error)
+	InvalidateByUserID(userID uuid.UUID) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{
+		db: db,
+	}
+}
+
+func (r *passwordResetRepository) WithTx(tx *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: tx}
+}
+
+func (r *passwordResetRepository) Create(resetToken *PasswordResetToken) error {
+	return r.db.Create(resetToken).Error
+}
+
+func (r *passwordResetRepository) FindByHash(hash string) (*PasswordResetToken, error) {
+	var resetToken PasswordResetToken
+
+	result := r.db.Where("token_hash = ?", hash).First(&resetToken)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &resetToken, nil
+}
+
+// MarkUsed mengembalikan false kalau token sudah terpakai lebih dulu
+func (r *passwordResetRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := r.db.
+		Model(&PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now().UTC())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// InvalidateByUserID menandai semua token yang belum terpakai sebagai terpakai
+func (r *passwordResetRepository) InvalidateByUserID(userID uuid.UUID) error {
+	return r.db.
+		Model(&PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now().UTC()).
+		Error
+}
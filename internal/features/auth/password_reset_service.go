@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/util/mailer"
+	util "go-fiber-api/internal/util/password"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const passwordResetTTL = time.Hour
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+type PasswordResetService interface {
+	ForgotPassword(req *ForgotPasswordRequest) error
+	ResetPassword(req *ResetPasswordRequest) error
+}
+
+type passwordResetService struct {
+	db          *gorm.DB
+	userRepo    UserRepository
+	resetRepo   PasswordResetRepository
+	sessionRepo SessionRepository
+	mailer      mailer.Mailer
+	appBaseURL  string
+}
+
+func NewPasswordResetService(
+	db *gorm.DB,
+	userRepo UserRepository,
+	resetRepo PasswordResetRepository,
+	sessionRepo SessionRepository,
+	mail mailer.Mailer,
+	appBaseURL string,
+) PasswordResetService {
+	return &passwordResetService{
+		db:          db,
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mail,
+		appBaseURL:  strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+// ForgotPassword tidak memberi tahu apakah email terdaftar atau tidak,
+// supaya endpoint ini tidak bisa dipakai untuk enumerasi user
+func (s *passwordResetService) ForgotPassword(req *ForgotPasswordRequest) error {
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	plain, hash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		resetRepo := s.resetRepo.WithTx(tx)
+
+		// hanya link terakhir yang berlaku
+		if err := resetRepo.InvalidateByUserID(user.ID); err != nil {
+			return err
+		}
+
+		return resetRepo.Create(&PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hash,
+			ExpiresAt: time.Now().UTC().Add(passwordResetTTL),
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appBaseURL, url.QueryEscape(plain))
+	mailer.SendAsync(s.mailer, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"We received a request to reset your password.\n\nOpen this link within %d minutes to choose a new password:\n%s\n\nIf you did not request this, you can ignore this email.",
+			int(passwordResetTTL.Minutes()),
+			link,
+		),
+	})
+
+	return nil
+}
+
+func (s *passwordResetService) ResetPassword(req *ResetPasswordRequest) error {
+	resetToken, err := s.resetRepo.FindByHash(token.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if resetToken.UsedAt != nil || time.Now().UTC().After(resetToken.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		used, err := s.resetRepo.WithTx(tx).MarkUsed(resetToken.ID)
+		if err != nil {
+			return err
+		}
+
+		if !used {
+			return ErrInvalidResetToken
+		}
+
+		if err := s.userRepo.WithTx(tx).UpdatePassword(resetToken.UserID, hashedPassword); err != nil {
+			return err
+		}
+
+		// semua login lama dianggap tidak aman lagi
+		_, err = s.sessionRepo.WithTx(tx).RevokeAllByUserID(resetToken.UserID, uuid.Nil, SessionRevokedPassword)
+		return err
+	})
+}
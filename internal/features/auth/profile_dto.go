@@ -0,0 +1,20 @@
+package auth
+
+// UpdateProfileRequest memakai pointer supaya field yang tidak dikirim
+// tidak ikut di-update (PATCH)
+type UpdateProfileRequest struct {
+	DisplayName      *string `json:"display_name" validate:"omitempty,max=100"`
+	PhoneNumber      *string `json:"phone_number" validate:"omitempty,e164"`
+	Locale           *string `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	MarketingConsent *bool   `json:"marketing_consent"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/upload"
+	"go-fiber-api/internal/util/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxAvatarSize = 2 * 1024 * 1024
+
+type ProfileHandler interface {
+	GetProfile(c *fiber.Ctx) error
+	UpdateProfile(c *fiber.Ctx) error
+	UploadAvatar(c *fiber.Ctx) error
+	ChangeEmail(c *fiber.Ctx) error
+	ChangePassword(c *fiber.Ctx) error
+}
+
+type profileHandler struct {
+	profileService ProfileService
+	storage        upload.Storage
+}
+
+func NewProfileHandler(service ProfileService, storage upload.Storage) ProfileHandler {
+	return &profileHandler{
+		profileService: service,
+		storage:        storage,
+	}
+}
+
+func (h *profileHandler) GetProfile(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	profile, err := h.profileService.GetProfile(claims.UserID)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to get profile")
+	}
+
+	return response.Success(c, "get profile success", profile)
+}
+
+func (h *profileHandler) UpdateProfile(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	var req UpdateProfileRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	profile, err := h.profileService.UpdateProfile(claims.UserID, &req)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to update profile")
+	}
+
+	return response.Success(c, "profile updated", profile)
+}
+
+func (h *profileHandler) UploadAvatar(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "avatar is required")
+	}
+
+	if fileHeader.Size > maxAvatarSize {
+		return response.Fail(c, fiber.StatusBadRequest, "avatar must be at most 2MB")
+	}
+
+	if !strings.HasPrefix(fileHeader.Header.Get(fiber.HeaderContentType), "image/") {
+		return response.Fail(c, fiber.StatusBadRequest, "avatar must be an image")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.storage.UploadToSupabaseStorage(ctx, fileHeader, "avatars")
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to upload avatar")
+	}
+
+	profile, err := h.profileService.UpdateAvatar(claims.UserID, result.PublicURL)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to update avatar")
+	}
+
+	return response.Success(c, "avatar updated", profile)
+}
+
+func (h *profileHandler) ChangeEmail(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	var req ChangeEmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	if err := h.profileService.RequestEmailChange(claims.UserID, &req); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCredentials):
+			return response.Fail(c, fiber.StatusBadRequest, "current password is incorrect")
+		case errors.Is(err, ErrEmailUnchanged):
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrEmailTaken):
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case errors.Is(err, ErrVerificationThrottled):
+			return response.Fail(c, fiber.StatusTooManyRequests, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to change email")
+	}
+
+	return response.SuccessNoData(c, "verification link sent to the new email address")
+}
+
+func (h *profileHandler) ChangePassword(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	var req ChangePasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	if err := h.profileService.ChangePassword(claims.UserID, claims.SessionID, &req); err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return response.Fail(c, fiber.StatusBadRequest, "current password is incorrect")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to change password")
+	}
+
+	return response.SuccessNoData(c, "password changed, other sessions have been signed out")
+}
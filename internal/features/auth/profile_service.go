@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/util/mailer"
+	util "go-fiber-api/internal/util/password"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrEmailUnchanged = errors.New("new email is the same as the current email")
+
+type ProfileService interface {
+	GetProfile(userID uuid.UUID) (*AuthResponse, error)
+	UpdateProfile(userID uuid.UUID, req *UpdateProfileRequest) (*AuthResponse, error)
+	UpdateAvatar(userID uuid.UUID, avatarURL string) (*AuthResponse, error)
+	RequestEmailChange(userID uuid.UUID, req *ChangeEmailRequest) error
+	ChangePassword(userID uuid.UUID, currentSessionID uuid.UUID, req *ChangePasswordRequest) error
+}
+
+type profileService struct {
+	db          *gorm.DB
+	userRepo    UserRepository
+	sessionRepo SessionRepository
+	mailer      mailer.Mailer
+	keys        *token.KeySet
+	appBaseURL  string
+}
+
+func NewProfileService(
+	db *gorm.DB,
+	userRepo UserRepository,
+	sessionRepo SessionRepository,
+	mail mailer.Mailer,
+	keys *token.KeySet,
+	appBaseURL string,
+) ProfileService {
+	return &profileService{
+		db:          db,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mail,
+		keys:        keys,
+		appBaseURL:  strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+func (s *profileService) GetProfile(userID uuid.UUID) (*AuthResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuthResponse(user), nil
+}
+
+func (s *profileService) UpdateProfile(userID uuid.UUID, req *UpdateProfileRequest) (*AuthResponse, error) {
+	updates := map[string]interface{}{}
+
+	if req.DisplayName != nil {
+		updates["display_name"] = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.PhoneNumber != nil {
+		updates["phone_number"] = strings.TrimSpace(*req.PhoneNumber)
+	}
+	if req.Locale != nil {
+		updates["locale"] = *req.Locale
+	}
+	if req.MarketingConsent != nil {
+		updates["marketing_consent"] = *req.MarketingConsent
+
+		// waktu persetujuan disimpan sebagai bukti consent
+		if *req.MarketingConsent {
+			updates["marketing_consent_at"] = time.Now().UTC()
+		} else {
+			updates["marketing_consent_at"] = nil
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.userRepo.UpdateProfile(userID, updates); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetProfile(userID)
+}
+
+func (s *profileService) UpdateAvatar(userID uuid.UUID, avatarURL string) (*AuthResponse, error) {
+	if err := s.userRepo.UpdateProfile(userID, map[string]interface{}{"avatar_url": avatarURL}); err != nil {
+		return nil, err
+	}
+
+	return s.GetProfile(userID)
+}
+
+// RequestEmailChange tidak langsung mengganti email, alamat baru
+// disimpan sebagai pending sampai link verifikasinya dibuka
+func (s *profileService) RequestEmailChange(userID uuid.UUID, req *ChangeEmailRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := util.CheckPassword(user.Password, req.CurrentPassword); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrEmailUnchanged
+	}
+
+	existing, err := s.userRepo.FindByEmail(newEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && existing.ID != user.ID {
+		return ErrEmailTaken
+	}
+
+	claimed, err := s.userRepo.ClaimVerificationSend(user.ID, time.Now().UTC().Add(-verificationResendInterval))
+	if err != nil {
+		return err
+	}
+
+	if !claimed {
+		return ErrVerificationThrottled
+	}
+
+	if err := s.userRepo.SetPendingEmail(user.ID, newEmail); err != nil {
+		return err
+	}
+
+	if err := sendVerificationLink(s.mailer, s.keys, s.appBaseURL, user.ID, newEmail); err != nil {
+		return err
+	}
+
+	// pemberitahuan ke alamat lama, kalau bukan user sendiri yang meminta
+	mailer.SendAsync(s.mailer, mailer.Message{
+		To:      user.Email,
+		Subject: "Email change requested",
+		Body:    "A request was made to change the email address of your account to " + newEmail + ".\n\nIf this was not you, reset your password immediately.",
+	})
+
+	return nil
+}
+
+// ChangePassword me-revoke semua session lain, session yang sedang dipakai tetap aktif
+func (s *profileService) ChangePassword(userID uuid.UUID, currentSessionID uuid.UUID, req *ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := util.CheckPassword(user.Password, req.CurrentPassword); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).UpdatePassword(user.ID, hashedPassword); err != nil {
+			return err
+		}
+
+		_, err := s.sessionRepo.WithTx(tx).RevokeAllByUserID(user.ID, currentSessionID, SessionRevokedPassword)
+		return err
+	})
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"go-fiber-api/internal/util/permission"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionMetadata diisi dari request (IP, user agent, device)
+type SessionMetadata struct {
+	Device    string
+	IPAddress string
+	UserAgent string
+}
+
+type IssuedSession struct {
+	SessionID    uuid.UUID
+	UserID       uuid.UUID
+	Role         permission.Role
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse dikirim di body hanya untuk client dengan header
+// X-Auth-Mode: token, browser tetap memakai cookie
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type LoginTokenResponse struct {
+	User *AuthResponse `json:"user"`
+	TokenResponse
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"time"
+
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SessionRevokedLogout         = "LOGOUT"
+	SessionRevokedTokenReuse     = "REFRESH_TOKEN_REUSE"
+	SessionRevokedByUser         = "REVOKED_BY_USER"
+	SessionRevokedPassword       = "PASSWORD_RESET"
+	SessionRevokedAccountDeleted = "ACCOUNT_DELETED"
+)
+
+// Session mewakili satu login (satu "family" refresh token)
+type Session struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	Device    string `gorm:"type:varchar(100)"`
+	IPAddress string `gorm:"type:varchar(64)"`
+	UserAgent string `gorm:"type:text"`
+
+	LastUsedAt    time.Time  `gorm:"not null"`
+	ExpiresAt     time.Time  `gorm:"not null"`
+	RevokedAt     *time.Time `gorm:"index"`
+	RevokedReason string     `gorm:"type:varchar(50)"`
+
+	// batas umur session sejak login, refresh tidak boleh melewatinya.
+	// kosong untuk session yang dibuat sebelum kolom ini ada
+	AbsoluteExpiresAt *time.Time
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// absoluteExpiry mengembalikan batas umur session, session lama yang
+// belum punya kolom ini dihitung dari waktu login
+func (s *Session) absoluteExpiry() time.Time {
+	if s.AbsoluteExpiresAt != nil {
+		return *s.AbsoluteExpiresAt
+	}
+	return s.CreatedAt.Add(token.RefreshTokenTTL)
+}
+
+// RefreshToken disimpan dalam bentuk hash, token yang sudah di-rotate
+// tetap disimpan supaya reuse bisa dideteksi
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+
+	ExpiresAt time.Time `gorm:"not null"`
+	RotatedAt *time.Time
+
+	// Relations
+	Session Session `gorm:"foreignKey:SessionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	WithTx(tx *gorm.DB) SessionRepository
+
+	Create(session *Session) error
+	FindByID(id uuid.UUID) (*Session, error)
+	FindActiveByUserID(userID uuid.UUID) ([]Session, error)
+	Touch(id uuid.UUID, ipAddress, userAgent string, expiresAt time.Time) error
+	TouchLastUsed(id uuid.UUID) error
+	Revoke(id uuid.UUID, reason string) error
+	RevokeAllByUserID(userID uuid.UUID, exceptID uuid.UUID, reason string) (int64, error)
+
+	CreateRefreshToken(refreshToken *RefreshToken) error
+	FindRefreshTokenByHash(hash string) (*RefreshToken, error)
+	MarkRefreshTokenRotated(id uuid.UUID) (bool, error)
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+func (r *sessionRepository) WithTx(tx *gorm.DB) SessionRepository {
+	return &sessionRepository{db: tx}
+}
+
+func (r *sessionRepository) Create(session *Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(id uuid.UUID) (*Session, error) {
+	var session Session
+
+	result := r.db.Where("id = ?", id).First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepository) FindActiveByUserID(userID uuid.UUID) ([]Session, error) {
+	var sessions []Session
+
+	result := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now().UTC()).
+		Order("last_used_at DESC").
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) Touch(id uuid.UUID, ipAddress, userAgent string, expiresAt time.Time) error {
+	updates := map[string]interface{}{
+		"last_used_at": time.Now().UTC(),
+		"ip_address":   ipAddress,
+		"user_agent":   userAgent,
+		"expires_at":   expiresAt,
+	}
+
+	return r.db.
+		Model(&Session{}).
+		Where("id = ?", id).
+		Updates(updates).
+		Error
+}
+
+func (r *sessionRepository) TouchLastUsed(id uuid.UUID) error {
+	return r.db.
+		Model(&Session{}).
+		Where("id = ?", id).
+		Update("last_used_at", time.Now().UTC()).
+		Error
+}
+
+func (r *sessionRepository) Revoke(id uuid.UUID, reason string) error {
+	updates := map[string]interface{}{
+		"revoked_at":     time.Now().UTC(),
+		"revoked_reason": reason,
+	}
+
+	return r.db.
+		Model(&Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(updates).
+		Error
+}
+
+// RevokeAllByUserID me-revoke semua session aktif user kecuali exceptID
+// (isi uuid.Nil untuk me-revoke semuanya)
+func (r *sessionRepository) RevokeAllByUserID(userID uuid.UUID, exceptID uuid.UUID, reason string) (int64, error) {
+	updates := map[string]interface{}{
+		"revoked_at":     time.Now().UTC(),
+		"revoked_reason": reason,
+	}
+
+	result := r.db.
+		Model(&Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Updates(updates)
+
+	return result.RowsAffected, result.Error
+}
+
+func (r *sessionRepository) CreateRefreshToken(refreshToken *RefreshToken) error {
+	return r.db.Create(refreshToken).Error
+}
+
+func (r *sessionRepository) FindRefreshTokenByHash(hash string) (*RefreshToken, error) {
+	var refreshToken RefreshToken
+
+	result := r.db.
+		Preload("Session").
+		Where("token_hash = ?", hash).
+		First(&refreshToken)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &refreshToken, nil
+}
+
+// MarkRefreshTokenRotated mengembalikan false kalau token sudah pernah
+// di-rotate sebelumnya (misal dua request refresh berjalan bersamaan)
+func (r *sessionRepository) MarkRefreshTokenRotated(id uuid.UUID) (bool, error) {
+	result := r.db.
+		Model(&RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL", id).
+		Update("rotated_at", time.Now().UTC())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrSessionExpired      = errors.New("session has expired")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// last_used_at cukup di-update sesekali, tidak perlu tiap request
+const sessionTouchInterval = time.Minute
+
+type SessionService interface {
+	CreateSession(userID uuid.UUID, meta *SessionMetadata) (*IssuedSession, error)
+	RefreshSession(refreshToken string, meta *SessionMetadata) (*IssuedSession, error)
+	RevokeSession(sessionID uuid.UUID, reason string) error
+	RevokeByRefreshToken(refreshToken string, reason string) error
+	ValidateSession(sessionID uuid.UUID, userID uuid.UUID) error
+	GetUserSessions(userID uuid.UUID, currentSessionID uuid.UUID) ([]SessionResponse, error)
+	RevokeUserSession(userID uuid.UUID, sessionID uuid.UUID) error
+	RevokeOtherSessions(userID uuid.UUID, currentSessionID uuid.UUID) (int64, error)
+}
+
+type sessionService struct {
+	db          *gorm.DB
+	sessionRepo SessionRepository
+	userRepo    UserRepository
+}
+
+func NewSessionService(db *gorm.DB, sessionRepo SessionRepository, userRepo UserRepository) SessionService {
+	return &sessionService{
+		db:          db,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *sessionService) CreateSession(userID uuid.UUID, meta *SessionMetadata) (*IssuedSession, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(token.RefreshTokenTTL)
+
+	plain, hash, err := token.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		UserID:            userID,
+		Device:            meta.Device,
+		IPAddress:         meta.IPAddress,
+		UserAgent:         meta.UserAgent,
+		LastUsedAt:        now,
+		ExpiresAt:         expiresAt,
+		AbsoluteExpiresAt: &expiresAt,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithTx(tx)
+
+		if err := sessionRepo.Create(session); err != nil {
+			return err
+		}
+
+		return sessionRepo.CreateRefreshToken(&RefreshToken{
+			SessionID: session.ID,
+			TokenHash: hash,
+			ExpiresAt: expiresAt,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &IssuedSession{
+		SessionID:    session.ID,
+		UserID:       userID,
+		Role:         user.Role,
+		RefreshToken: plain,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+func (s *sessionService) RefreshSession(refreshToken string, meta *SessionMetadata) (*IssuedSession, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	current, err := s.sessionRepo.FindRefreshTokenByHash(token.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	session := current.Session
+
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	// Token lama dipakai lagi: kemungkinan token dicuri,
+	// matikan seluruh session (family) milik token ini
+	if current.RotatedAt != nil {
+		if err := s.sessionRepo.Revoke(session.ID, SessionRevokedTokenReuse); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	now := time.Now().UTC()
+	if now.After(current.ExpiresAt) || now.After(session.ExpiresAt) || now.After(session.absoluteExpiry()) {
+		return nil, ErrSessionExpired
+	}
+
+	// role dibaca ulang supaya perubahan role ikut masuk ke access token baru
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, hash, err := token.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// refresh memperpanjang session, tapi tidak melewati batas sejak login
+	expiresAt := now.Add(token.RefreshTokenTTL)
+	if absolute := session.absoluteExpiry(); expiresAt.After(absolute) {
+		expiresAt = absolute
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		sessionRepo := s.sessionRepo.WithTx(tx)
+
+		rotated, err := sessionRepo.MarkRefreshTokenRotated(current.ID)
+		if err != nil {
+			return err
+		}
+
+		if !rotated {
+			return ErrRefreshTokenReused
+		}
+
+		if err := sessionRepo.CreateRefreshToken(&RefreshToken{
+			SessionID: session.ID,
+			TokenHash: hash,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return err
+		}
+
+		return sessionRepo.Touch(session.ID, meta.IPAddress, meta.UserAgent, expiresAt)
+	})
+
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if revokeErr := s.sessionRepo.Revoke(session.ID, SessionRevokedTokenReuse); revokeErr != nil {
+			return nil, revokeErr
+		}
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &IssuedSession{
+		SessionID:    session.ID,
+		UserID:       session.UserID,
+		Role:         user.Role,
+		RefreshToken: plain,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+func (s *sessionService) RevokeSession(sessionID uuid.UUID, reason string) error {
+	return s.sessionRepo.Revoke(sessionID, reason)
+}
+
+func (s *sessionService) RevokeByRefreshToken(refreshToken string, reason string) error {
+	current, err := s.sessionRepo.FindRefreshTokenByHash(token.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	return s.sessionRepo.Revoke(current.SessionID, reason)
+}
+
+func (s *sessionService) ValidateSession(sessionID uuid.UUID, userID uuid.UUID) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// session harus milik user yang ada di token
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	if session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+
+	now := time.Now().UTC()
+	if now.After(session.ExpiresAt) || now.After(session.absoluteExpiry()) {
+		return ErrSessionExpired
+	}
+
+	if now.Sub(session.LastUsedAt) > sessionTouchInterval {
+		if err := s.sessionRepo.TouchLastUsed(session.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *sessionService) GetUserSessions(userID uuid.UUID, currentSessionID uuid.UUID) ([]SessionResponse, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentSessionID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *sessionService) RevokeUserSession(userID uuid.UUID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	// jangan bocorkan keberadaan session milik user lain
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	return s.sessionRepo.Revoke(session.ID, SessionRevokedByUser)
+}
+
+func (s *sessionService) RevokeOtherSessions(userID uuid.UUID, currentSessionID uuid.UUID) (int64, error) {
+	return s.sessionRepo.RevokeAllByUserID(userID, currentSessionID, SessionRevokedByUser)
+}
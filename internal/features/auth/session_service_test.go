@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-api/internal/util/testdb"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type sessionFixture struct {
+	db      *gorm.DB
+	service SessionService
+	user    User
+}
+
+func newSessionFixture(t *testing.T) *sessionFixture {
+	t.Helper()
+
+	db := testdb.Open(t, &User{}, &Session{}, &RefreshToken{})
+
+	user := User{Email: uuid.NewString() + "@example.com", Password: "hashed"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	service := NewSessionService(db, NewSessionRepository(db), NewUserRepository(db))
+
+	return &sessionFixture{db: db, service: service, user: user}
+}
+
+func (f *sessionFixture) login(t *testing.T) *IssuedSession {
+	t.Helper()
+
+	issued, err := f.service.CreateSession(f.user.ID, &SessionMetadata{Device: "test"})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	return issued
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	f := newSessionFixture(t)
+	first := f.login(t)
+
+	second, err := f.service.RefreshSession(first.RefreshToken, &SessionMetadata{})
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+
+	if second.SessionID != first.SessionID {
+		t.Fatalf("refresh created session %s, want %s", second.SessionID, first.SessionID)
+	}
+
+	// token yang sudah di-rotate dipakai lagi
+	if _, err := f.service.RefreshSession(first.RefreshToken, &SessionMetadata{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reused token error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	// seluruh family ikut mati, termasuk token terbaru
+	if _, err := f.service.RefreshSession(second.RefreshToken, &SessionMetadata{}); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("latest token error = %v, want %v", err, ErrSessionRevoked)
+	}
+
+	if err := f.service.ValidateSession(first.SessionID, f.user.ID); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("ValidateSession error = %v, want %v", err, ErrSessionRevoked)
+	}
+}
+
+func TestRefreshSessionCappedAtAbsoluteExpiry(t *testing.T) {
+	f := newSessionFixture(t)
+	issued := f.login(t)
+
+	absolute := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	if err := f.db.Model(&Session{}).Where("id = ?", issued.SessionID).Update("absolute_expires_at", absolute).Error; err != nil {
+		t.Fatalf("update absolute expiry: %v", err)
+	}
+
+	refreshed, err := f.service.RefreshSession(issued.RefreshToken, &SessionMetadata{})
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+
+	if !refreshed.ExpiresAt.Equal(absolute) {
+		t.Fatalf("refreshed expiry = %s, want %s", refreshed.ExpiresAt, absolute)
+	}
+
+	// batas login sudah lewat: refresh dan akses ditolak walau expires_at masih jauh
+	past := time.Now().UTC().Add(-time.Minute)
+	if err := f.db.Model(&Session{}).Where("id = ?", issued.SessionID).Updates(map[string]interface{}{
+		"absolute_expires_at": past,
+		"expires_at":          time.Now().UTC().Add(time.Hour),
+	}).Error; err != nil {
+		t.Fatalf("update session: %v", err)
+	}
+
+	if _, err := f.service.RefreshSession(refreshed.RefreshToken, &SessionMetadata{}); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("RefreshSession error = %v, want %v", err, ErrSessionExpired)
+	}
+
+	if err := f.service.ValidateSession(issued.SessionID, f.user.ID); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("ValidateSession error = %v, want %v", err, ErrSessionExpired)
+	}
+}
+
+func TestValidateSessionRejectsOtherUser(t *testing.T) {
+	f := newSessionFixture(t)
+	issued := f.login(t)
+
+	if err := f.service.ValidateSession(issued.SessionID, f.user.ID); err != nil {
+		t.Fatalf("ValidateSession: %v", err)
+	}
+
+	if err := f.service.ValidateSession(issued.SessionID, uuid.New()); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("ValidateSession error = %v, want %v", err, ErrSessionNotFound)
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTwoFactor menyimpan secret TOTP milik user. Dianggap aktif
+// setelah ConfirmedAt terisi (user sudah berhasil memasukkan kode pertama)
+type UserTwoFactor struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Secret string    `gorm:"type:varchar(64);not null"`
+
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type RecoveryCode struct {
+	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	CodeHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	UsedAt   *time.Time
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+}
+
+// LoginChallenge dibuat setelah password benar untuk user yang
+// memakai 2FA, ditukar dengan session setelah kode TOTP diverifikasi
+type LoginChallenge struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"errors"
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/validation"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *authHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	var req TwoFactorLoginRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	challengeUserID, err := h.twoFactorService.ChallengeUserID(req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidLoginChallenge) {
+			return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: "+err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to verify two-factor code")
+	}
+
+	user, err := h.authService.GetUser(challengeUserID)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to login user")
+	}
+
+	meta := sessionMetadata(c)
+
+	// batas per challenge saja tidak cukup karena setiap login dengan password
+	// yang benar membuat challenge baru, jadi tebakan kode ikut dihitung di
+	// throttle akun dan IP yang sama dengan password
+	retryAfter, err := h.loginThrottle.Check(user.Email, meta)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to verify two-factor code")
+	}
+
+	if retryAfter > 0 {
+		return loginLocked(c, retryAfter)
+	}
+
+	userID, err := h.twoFactorService.CompleteChallenge(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidTwoFactorCode):
+			if err := h.loginThrottle.RegisterFailure(user.Email, meta); err != nil {
+				log.Println("register login failure error:", err)
+			}
+			return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: "+err.Error())
+		case errors.Is(err, ErrInvalidLoginChallenge),
+			errors.Is(err, ErrTwoFactorNotEnabled):
+			return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: "+err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to verify two-factor code")
+	}
+
+	if err := h.loginThrottle.RegisterSuccess(user.Email); err != nil {
+		log.Println("reset login throttle error:", err)
+	}
+
+	session, err := h.sessionService.CreateSession(userID, meta)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to create session")
+	}
+
+	tokens, err := issueTokens(c, h.tokens, session)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to generate token")
+	}
+
+	return loginSuccess(c, user, tokens)
+}
+
+func (h *authHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	setup, err := h.twoFactorService.Setup(claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to set up two-factor authentication")
+	}
+
+	return response.Success(c, "scan the provisioning uri with your authenticator app, then confirm with a code", setup)
+}
+
+func (h *authHandler) EnableTwoFactor(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	var req TwoFactorCodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	codes, err := h.twoFactorService.Enable(claims.UserID, req.Code)
+	if err != nil {
+		return twoFactorError(c, err, "failed to enable two-factor authentication")
+	}
+
+	return response.Success(c, "two-factor authentication enabled, store these recovery codes safely", codes)
+}
+
+func (h *authHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	var req TwoFactorCodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	if err := h.twoFactorService.Disable(claims.UserID, req.Code); err != nil {
+		return twoFactorError(c, err, "failed to disable two-factor authentication")
+	}
+
+	return response.SuccessNoData(c, "two-factor authentication disabled")
+}
+
+func (h *authHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	var req TwoFactorCodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(claims.UserID, req.Code)
+	if err != nil {
+		return twoFactorError(c, err, "failed to regenerate recovery codes")
+	}
+
+	return response.Success(c, "recovery codes regenerated", codes)
+}
+
+func twoFactorError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		return response.Fail(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrTwoFactorAlreadyEnabled):
+		return response.Fail(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, ErrTwoFactorNotSetup),
+		errors.Is(err, ErrTwoFactorNotEnabled):
+		return response.Fail(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return response.Fail(c, fiber.StatusInternalServerError, fallback)
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorRepository interface {
+	WithTx(tx *gorm.DB) TwoFactorRepository
+	FindByUserID(userID uuid.UUID) (*UserTwoFactor, error)
+	Upsert(twoFactor *UserTwoFactor) error
+	Confirm(userID uuid.UUID, step int64) error
+	ClaimStep(userID uuid.UUID, step int64) (bool, error)
+	DeleteByUserID(userID uuid.UUID) error
+
+	ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error
+	UseRecoveryCode(userID uuid.UUID, hash string) (bool, error)
+	DeleteRecoveryCodes(userID uuid.UUID) error
+
+	CreateChallenge(challenge *LoginChallenge) error
+	FindChallengeByHash(hash string) (*LoginChallenge, error)
+	RegisterChallengeAttempt(id uuid.UUID, maxAttempts int) (bool, error)
+	MarkChallengeUsed(id uuid.UUID) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{
+		db: db,
+	}
+}
+
+func (r *twoFactorRepository) WithTx(tx *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: tx}
+}
+
+// FindByUserID mengembalikan nil tanpa error kalau user belum pernah setup 2FA
+func (r *twoFactorRepository) FindByUserID(userID uuid.UUID) (*UserTwoFactor, error) {
+	var twoFactor UserTwoFactor
+
+	err := r.db.Where("user_id = ?", userID).Take(&twoFactor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Upsert(twoFactor *UserTwoFactor) error {
+	return r.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_used_step", "updated_at"}),
+		}).
+		Create(twoFactor).
+		Error
+}
+
+func (r *twoFactorRepository) Confirm(userID uuid.UUID, step int64) error {
+	updates := map[string]interface{}{
+		"confirmed_at":   time.Now().UTC(),
+		"last_used_step": step,
+	}
+
+	return r.db.
+		Model(&UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Updates(updates).
+		Error
+}
+
+// ClaimStep mengembalikan false kalau kode untuk step ini (atau sesudahnya)
+// sudah pernah dipakai, supaya kode yang sama tidak bisa di-replay
+func (r *twoFactorRepository) ClaimStep(userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.
+		Model(&UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) DeleteByUserID(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&UserTwoFactor{}).Error
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, hashes []string) error {
+	if err := r.DeleteRecoveryCodes(userID); err != nil {
+		return err
+	}
+
+	codes := make([]RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, RecoveryCode{
+			UserID:   userID,
+			CodeHash: hash,
+		})
+	}
+
+	return r.db.Create(&codes).Error
+}
+
+// UseRecoveryCode mengembalikan false kalau kode tidak ada atau sudah terpakai
+func (r *twoFactorRepository) UseRecoveryCode(userID uuid.UUID, hash string) (bool, error) {
+	result := r.db.
+		Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now().UTC())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) DeleteRecoveryCodes(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error
+}
+
+func (r *twoFactorRepository) CreateChallenge(challenge *LoginChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *twoFactorRepository) FindChallengeByHash(hash string) (*LoginChallenge, error) {
+	var challenge LoginChallenge
+
+	result := r.db.Where("token_hash = ?", hash).First(&challenge)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &challenge, nil
+}
+
+// RegisterChallengeAttempt mengembalikan false kalau jatah percobaan sudah habis
+func (r *twoFactorRepository) RegisterChallengeAttempt(id uuid.UUID, maxAttempts int) (bool, error) {
+	result := r.db.
+		Model(&LoginChallenge{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) MarkChallengeUsed(id uuid.UUID) (bool, error) {
+	result := r.db.
+		Model(&LoginChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now().UTC())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
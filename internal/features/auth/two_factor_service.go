@@ -0,0 +1,353 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/totp"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorIssuer = "go-fiber-api"
+
+	loginChallengeTTL         = 5 * time.Minute
+	loginChallengeMaxAttempts = 5
+
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotSetup       = errors.New("two-factor authentication has not been set up")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidLoginChallenge   = errors.New("invalid or expired login challenge")
+)
+
+type TwoFactorService interface {
+	Setup(userID uuid.UUID) (*TwoFactorSetupResponse, error)
+	Enable(userID uuid.UUID, code string) (*RecoveryCodesResponse, error)
+	Disable(userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(userID uuid.UUID, code string) (*RecoveryCodesResponse, error)
+	IsEnabled(userID uuid.UUID) (bool, error)
+	CreateChallenge(userID uuid.UUID) (*LoginChallengeResponse, error)
+	ChallengeUserID(challengeToken string) (uuid.UUID, error)
+	CompleteChallenge(req *TwoFactorLoginRequest) (uuid.UUID, error)
+}
+
+type twoFactorService struct {
+	db            *gorm.DB
+	userRepo      UserRepository
+	twoFactorRepo TwoFactorRepository
+}
+
+func NewTwoFactorService(db *gorm.DB, userRepo UserRepository, twoFactorRepo TwoFactorRepository) TwoFactorService {
+	return &twoFactorService{
+		db:            db,
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+	}
+}
+
+// Setup membuat secret baru yang belum aktif sampai dikonfirmasi lewat Enable
+func (s *twoFactorService) Setup(userID uuid.UUID) (*TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil && current.ConfirmedAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.Upsert(&UserTwoFactor{
+		UserID: userID,
+		Secret: secret,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Enable(userID uuid.UUID, code string) (*RecoveryCodesResponse, error) {
+	current, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		return nil, ErrTwoFactorNotSetup
+	}
+
+	if current.ConfirmedAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(current.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		twoFactorRepo := s.twoFactorRepo.WithTx(tx)
+
+		if err := twoFactorRepo.Confirm(userID, step); err != nil {
+			return err
+		}
+
+		return twoFactorRepo.ReplaceRecoveryCodes(userID, hashes)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *twoFactorService) Disable(userID uuid.UUID, code string) error {
+	current, err := s.enabledTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.verifyCode(current, code, true); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		twoFactorRepo := s.twoFactorRepo.WithTx(tx)
+
+		if err := twoFactorRepo.DeleteRecoveryCodes(userID); err != nil {
+			return err
+		}
+
+		return twoFactorRepo.DeleteByUserID(userID)
+	})
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(userID uuid.UUID, code string) (*RecoveryCodesResponse, error) {
+	current, err := s.enabledTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// recovery code tidak boleh dipakai untuk membuat recovery code baru
+	if err := s.verifyCode(current, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *twoFactorService) IsEnabled(userID uuid.UUID) (bool, error) {
+	current, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	return current != nil && current.ConfirmedAt != nil, nil
+}
+
+func (s *twoFactorService) CreateChallenge(userID uuid.UUID) (*LoginChallengeResponse, error) {
+	plain, hash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().UTC().Add(loginChallengeTTL)
+
+	if err := s.twoFactorRepo.CreateChallenge(&LoginChallenge{
+		UserID:    userID,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &LoginChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    plain,
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// ChallengeUserID mengembalikan pemilik challenge yang masih berlaku tanpa
+// memverifikasi kode, dipakai untuk mengecek login throttle akun tersebut
+func (s *twoFactorService) ChallengeUserID(challengeToken string) (uuid.UUID, error) {
+	challenge, err := s.activeChallenge(challengeToken)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return challenge.UserID, nil
+}
+
+// CompleteChallenge mengembalikan user id pemilik challenge kalau kode benar
+func (s *twoFactorService) CompleteChallenge(req *TwoFactorLoginRequest) (uuid.UUID, error) {
+	challenge, err := s.activeChallenge(req.ChallengeToken)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// batasi tebakan kode per challenge
+	allowed, err := s.twoFactorRepo.RegisterChallengeAttempt(challenge.ID, loginChallengeMaxAttempts)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if !allowed {
+		return uuid.Nil, ErrInvalidLoginChallenge
+	}
+
+	current, err := s.enabledTwoFactor(challenge.UserID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := s.verifyCode(current, req.Code, true); err != nil {
+		return uuid.Nil, err
+	}
+
+	used, err := s.twoFactorRepo.MarkChallengeUsed(challenge.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if !used {
+		return uuid.Nil, ErrInvalidLoginChallenge
+	}
+
+	return challenge.UserID, nil
+}
+
+func (s *twoFactorService) activeChallenge(challengeToken string) (*LoginChallenge, error) {
+	challenge, err := s.twoFactorRepo.FindChallengeByHash(token.HashToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidLoginChallenge
+		}
+		return nil, err
+	}
+
+	if challenge.UsedAt != nil || time.Now().UTC().After(challenge.ExpiresAt) {
+		return nil, ErrInvalidLoginChallenge
+	}
+
+	return challenge, nil
+}
+
+func (s *twoFactorService) enabledTwoFactor(userID uuid.UUID) (*UserTwoFactor, error) {
+	current, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil || current.ConfirmedAt == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	return current, nil
+}
+
+// verifyCode menerima kode TOTP 6 digit, atau recovery code kalau allowRecovery
+func (s *twoFactorService) verifyCode(twoFactor *UserTwoFactor, code string, allowRecovery bool) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		claimed, err := s.twoFactorRepo.ClaimStep(twoFactor.UserID, step)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			return ErrInvalidTwoFactorCode
+		}
+
+		return nil
+	}
+
+	if !allowRecovery {
+		return ErrInvalidTwoFactorCode
+	}
+
+	used, err := s.twoFactorRepo.UseRecoveryCode(twoFactor.UserID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes menghasilkan kode berformat xxxxx-xxxxx,
+// yang disimpan di database hanya hash-nya
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 6)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return token.HashToken(normalized)
+}
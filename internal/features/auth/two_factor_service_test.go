@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-api/internal/util/testdb"
+	"go-fiber-api/internal/util/totp"
+
+	"github.com/google/uuid"
+)
+
+// totpCode menghitung kode seperti aplikasi authenticator
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/int64(totp.Period.Seconds())))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1_000_000)
+}
+
+type twoFactorFixture struct {
+	service       TwoFactorService
+	userID        uuid.UUID
+	secret        string
+	recoveryCodes []string
+}
+
+// newTwoFactorFixture membuat user dengan 2FA yang sudah aktif
+func newTwoFactorFixture(t *testing.T) *twoFactorFixture {
+	t.Helper()
+
+	db := testdb.Open(t, &User{}, &UserTwoFactor{}, &RecoveryCode{}, &LoginChallenge{})
+
+	user := User{Email: uuid.NewString() + "@example.com", Password: "hashed"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	service := NewTwoFactorService(db, NewUserRepository(db), NewTwoFactorRepository(db))
+
+	setup, err := service.Setup(user.ID)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	if _, err := service.Enable(user.ID, "abcdef"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("Enable with invalid code error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	recovery, err := service.Enable(user.ID, totpCode(t, setup.Secret, time.Now()))
+	if err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+
+	if len(recovery.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("recovery codes = %d, want %d", len(recovery.RecoveryCodes), recoveryCodeCount)
+	}
+
+	return &twoFactorFixture{
+		service:       service,
+		userID:        user.ID,
+		secret:        setup.Secret,
+		recoveryCodes: recovery.RecoveryCodes,
+	}
+}
+
+func (f *twoFactorFixture) challenge(t *testing.T) string {
+	t.Helper()
+
+	challenge, err := f.service.CreateChallenge(f.userID)
+	if err != nil {
+		t.Fatalf("CreateChallenge: %v", err)
+	}
+
+	return challenge.ChallengeToken
+}
+
+func TestTwoFactorLoginWithTOTP(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	challenge := f.challenge(t)
+
+	// kode yang dipakai saat Enable tidak boleh dipakai lagi untuk login
+	replayed := totpCode(t, f.secret, time.Now())
+	if _, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: challenge, Code: replayed}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("replayed code error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	next := totpCode(t, f.secret, time.Now().Add(totp.Period))
+	userID, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: challenge, Code: next})
+	if err != nil {
+		t.Fatalf("CompleteChallenge: %v", err)
+	}
+
+	if userID != f.userID {
+		t.Fatalf("user id = %s, want %s", userID, f.userID)
+	}
+
+	// challenge hanya bisa ditukar sekali
+	if _, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: challenge, Code: next}); !errors.Is(err, ErrInvalidLoginChallenge) {
+		t.Fatalf("used challenge error = %v, want %v", err, ErrInvalidLoginChallenge)
+	}
+}
+
+func TestTwoFactorLoginWithRecoveryCode(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	code := f.recoveryCodes[0]
+
+	if _, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: f.challenge(t), Code: code}); err != nil {
+		t.Fatalf("CompleteChallenge: %v", err)
+	}
+
+	if _, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: f.challenge(t), Code: code}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("reused recovery code error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	// huruf besar dan tanpa tanda hubung tetap diterima
+	normalized := strings.ToUpper(strings.ReplaceAll(f.recoveryCodes[1], "-", ""))
+	if _, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: f.challenge(t), Code: normalized}); err != nil {
+		t.Fatalf("CompleteChallenge with normalized code: %v", err)
+	}
+
+	// recovery code tidak bisa dipakai untuk membuat recovery code baru
+	if _, err := f.service.RegenerateRecoveryCodes(f.userID, f.recoveryCodes[2]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("RegenerateRecoveryCodes error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+}
+
+func TestTwoFactorChallengeAttemptLimit(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	challenge := f.challenge(t)
+
+	for i := 0; i < loginChallengeMaxAttempts; i++ {
+		if _, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: challenge, Code: "wrong-code"}); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("attempt %d error = %v, want %v", i+1, err, ErrInvalidTwoFactorCode)
+		}
+	}
+
+	if _, err := f.service.CompleteChallenge(&TwoFactorLoginRequest{ChallengeToken: challenge, Code: f.recoveryCodes[0]}); !errors.Is(err, ErrInvalidLoginChallenge) {
+		t.Fatalf("exhausted challenge error = %v, want %v", err, ErrInvalidLoginChallenge)
+	}
+}
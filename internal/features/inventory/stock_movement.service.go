@@ -1,27 +1,35 @@
 package inventory
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type stockMovementService struct {
+	db   *gorm.DB
 	repo StockMovementRepository
 }
 
 type StockMovementService interface {
-	AddStockIn(productID uuid.UUID, quantity int) error
-	AddStockOut(productID uuid.UUID, quantity int) error
+	AddStockIn(merchantID uuid.UUID, productID uuid.UUID, quantity int) error
+	AddStockOut(merchantID uuid.UUID, productID uuid.UUID, quantity int) error
 	AddStockSale(productID uuid.UUID, quantity int) error
 }
 
-func NewStockMovementService(repo StockMovementRepository) StockMovementService {
-	return &stockMovementService{repo: repo}
+func NewStockMovementService(db *gorm.DB, repo StockMovementRepository) StockMovementService {
+	return &stockMovementService{db: db, repo: repo}
 }
 
-func (s *stockMovementService) AddStockIn(productID uuid.UUID, quantity int) error {
-	return s.repo.AddStockIn(productID, quantity)
+func (s *stockMovementService) AddStockIn(merchantID uuid.UUID, productID uuid.UUID, quantity int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.repo.WithTx(tx).AddStockIn(merchantID, productID, quantity)
+	})
 }
 
-func (s *stockMovementService) AddStockOut(productID uuid.UUID, quantity int) error {
-	return s.repo.AddStockOut(productID, quantity)
+func (s *stockMovementService) AddStockOut(merchantID uuid.UUID, productID uuid.UUID, quantity int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.repo.WithTx(tx).AddStockOut(merchantID, productID, quantity)
+	})
 }
 
 func (s *stockMovementService) AddStockSale(productID uuid.UUID, quantity int) error {
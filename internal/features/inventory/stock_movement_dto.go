@@ -3,6 +3,6 @@ package inventory
 import "github.com/google/uuid"
 
 type StockMovementDTO struct {
-	ProductID uuid.UUID `json:"product_id" validate:"required,uuid4"`
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
 	Quantity  int       `json:"quantity" validate:"required,gt=0"`
 }
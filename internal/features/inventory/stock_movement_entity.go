@@ -13,6 +13,8 @@ const (
 	StockOut    StockMovementType = "OUT"
 	StockAdjust StockMovementType = "ADJUST"
 	StockSale   StockMovementType = "SALE"
+	// StockReturn: barang dari refund yang dikembalikan ke stok
+	StockReturn StockMovementType = "RETURN"
 )
 
 type StockMovement struct {
@@ -24,7 +26,7 @@ type StockMovement struct {
 	Quantity int               `gorm:"type:int;not null"`
 
 	ReferenceID   *uuid.UUID `gorm:"type:uuid;index"`
-	ReferenceType string     `gorm:"type:varchar(50);index"` // TRANSACTION, RESTOCK, ADJUSTMENT
+	ReferenceType string     `gorm:"type:varchar(50);index"` // TRANSACTION, REFUND, RESTOCK, ADJUSTMENT
 
 	CreatedAt time.Time
 }
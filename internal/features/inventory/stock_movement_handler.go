@@ -2,13 +2,15 @@ package inventory
 
 import (
 	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/validation"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type StockMovementHandler interface {
 	AddStockIn(c *fiber.Ctx) error
-	// AddStockOut(c *fiber.Ctx) error
+	AddStockOut(c *fiber.Ctx) error
 }
 
 type stockMovementHandler struct {
@@ -22,15 +24,51 @@ func NewStockMovementHandler(service StockMovementService) StockMovementHandler
 }
 
 func (h *stockMovementHandler) AddStockIn(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("merchant_id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id")
+	}
+
 	var request StockMovementDTO
 	if err := c.BodyParser(&request); err != nil {
 		return response.Fail(c, fiber.StatusBadRequest, "invalid request body")
 	}
 
-	err := h.service.AddStockIn(request.ProductID, request.Quantity)
+	if errorMessages, err := validation.ValidateStruct(request); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	err = h.service.AddStockIn(merchantID, request.ProductID, request.Quantity)
 	if err != nil {
 		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
 	}
 
 	return response.SuccessNoData(c, "stock in added successfully")
 }
+
+func (h *stockMovementHandler) AddStockOut(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("merchant_id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id")
+	}
+
+	var request StockMovementDTO
+	if err := c.BodyParser(&request); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(request); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	err = h.service.AddStockOut(merchantID, request.ProductID, request.Quantity)
+	if err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return response.SuccessNoData(c, "stock out added successfully")
+}
@@ -10,9 +10,10 @@ import (
 
 type StockMovementRepository interface {
 	WithTx(tx *gorm.DB) StockMovementRepository
-	AddStockIn(productID uuid.UUID, quantity int) error
-	AddStockOut(productID uuid.UUID, quantity int) error
+	AddStockIn(merchantID uuid.UUID, productID uuid.UUID, quantity int) error
+	AddStockOut(merchantID uuid.UUID, productID uuid.UUID, quantity int) error
 	AddStockSale(productID uuid.UUID, quantity int) error
+	AddStockReturn(productID uuid.UUID, quantity int, refundID uuid.UUID) error
 }
 
 type stockMovementRepository struct {
@@ -29,39 +30,40 @@ func (r *stockMovementRepository) WithTx(tx *gorm.DB) StockMovementRepository {
 	return &stockMovementRepository{db: tx}
 }
 
-func (r *stockMovementRepository) AddStockIn(productID uuid.UUID, quantity int) error {
+func (r *stockMovementRepository) AddStockIn(merchantID uuid.UUID, productID uuid.UUID, quantity int) error {
 	if quantity <= 0 {
 		return errors.New("quantity must be greater than zero")
 	}
 
-	movement := &StockMovement{
-		ProductID: productID,
-		Quantity:  quantity,
-		Type:      StockIn,
-	}
-
-	if err := r.db.Create(movement).Error; err != nil {
-		return err
-	}
-
 	result := r.db.Model(&products.Product{}).
-		Where("id = ?", productID).
+		Where("id = ? AND merchant_id = ?", productID, merchantID).
 		Update("quantity", gorm.Expr("quantity + ?", quantity))
 
+	if result.Error != nil {
+		return result.Error
+	}
+
 	if result.RowsAffected == 0 {
 		return errors.New("product not found")
 	}
 
-	return result.Error
+	movement := &StockMovement{
+		ProductID: productID,
+		Quantity:  quantity,
+		Type:      StockIn,
+	}
+
+	return r.db.Create(movement).Error
 }
 
-func (r *stockMovementRepository) AddStockOut(productID uuid.UUID, quantity int) error {
+func (r *stockMovementRepository) AddStockOut(merchantID uuid.UUID, productID uuid.UUID, quantity int) error {
 	if quantity <= 0 {
 		return errors.New("quantity must be greater than zero")
 	}
 
 	result := r.db.Model(&products.Product{}).
-		Where("id = ? AND quantity >= ?", productID, quantity).
+		// stok yang sedang direservasi checkout tidak boleh dikeluarkan
+		Where("id = ? AND merchant_id = ? AND quantity - reserved_quantity >= ?", productID, merchantID, quantity).
 		Update("quantity", gorm.Expr("quantity - ?", quantity))
 
 	if result.RowsAffected == 0 {
@@ -106,3 +108,34 @@ func (r *stockMovementRepository) AddStockSale(productID uuid.UUID, quantity int
 
 	return r.db.Create(movement).Error
 }
+
+// AddStockReturn mengembalikan barang hasil refund ke stok dengan refund
+// sebagai referensi movement
+func (r *stockMovementRepository) AddStockReturn(productID uuid.UUID, quantity int, refundID uuid.UUID) error {
+	if quantity <= 0 {
+		return errors.New("quantity must be greater than zero")
+	}
+
+	// produk yang sudah dihapus tetap dicatat supaya stoknya konsisten
+	result := r.db.Unscoped().Model(&products.Product{}).
+		Where("id = ?", productID).
+		Update("quantity", gorm.Expr("quantity + ?", quantity))
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("product not found")
+	}
+
+	movement := &StockMovement{
+		ProductID:     productID,
+		Type:          StockReturn,
+		Quantity:      quantity,
+		ReferenceID:   &refundID,
+		ReferenceType: referenceTypeRefund,
+	}
+
+	return r.db.Create(movement).Error
+}
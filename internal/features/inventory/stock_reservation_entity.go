@@ -0,0 +1,30 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockReservationStatus string
+
+const (
+	ReservationActive    StockReservationStatus = "ACTIVE"
+	ReservationReleased  StockReservationStatus = "RELEASED"
+	ReservationConverted StockReservationStatus = "CONVERTED"
+)
+
+// StockReservation menahan stok untuk satu item transaksi sampai transaksi
+// lunas (dikonversi jadi SALE) atau gagal/kedaluwarsa (dilepas)
+type StockReservation struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+
+	ProductID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	TransactionID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	Quantity int                    `gorm:"type:int;not null"`
+	Status   StockReservationStatus `gorm:"type:varchar(20);not null;default:'ACTIVE';index"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,148 @@
+package inventory
+
+import (
+	"errors"
+
+	"go-fiber-api/internal/features/products"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// nilai StockMovement.ReferenceType
+const (
+	referenceTypeTransaction = "TRANSACTION"
+	referenceTypeRefund      = "REFUND"
+)
+
+type StockReservationRepository interface {
+	WithTx(tx *gorm.DB) StockReservationRepository
+	Reserve(transactionID uuid.UUID, productID uuid.UUID, quantity int) error
+	Release(transactionID uuid.UUID) error
+	Convert(transactionID uuid.UUID) (int, error)
+}
+
+type stockReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewStockReservationRepository(db *gorm.DB) StockReservationRepository {
+	return &stockReservationRepository{db: db}
+}
+
+func (r *stockReservationRepository) WithTx(tx *gorm.DB) StockReservationRepository {
+	return &stockReservationRepository{db: tx}
+}
+
+// Reserve menaikkan reserved_quantity hanya kalau stok tersedia masih
+// cukup, jadi dua checkout bersamaan tidak bisa memesan item terakhir
+func (r *stockReservationRepository) Reserve(transactionID uuid.UUID, productID uuid.UUID, quantity int) error {
+	if quantity <= 0 {
+		return errors.New("quantity must be greater than zero")
+	}
+
+	result := r.db.Model(&products.Product{}).
+		Where("id = ? AND quantity - reserved_quantity >= ?", productID, quantity).
+		Update("reserved_quantity", gorm.Expr("reserved_quantity + ?", quantity))
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+
+	reservation := &StockReservation{
+		ProductID:     productID,
+		TransactionID: transactionID,
+		Quantity:      quantity,
+		Status:        ReservationActive,
+	}
+
+	return r.db.Create(reservation).Error
+}
+
+// Release mengembalikan stok yang ditahan transaksi. Reservasi yang sudah
+// dilepas atau dikonversi dilewati, jadi aman dipanggil berulang
+func (r *stockReservationRepository) Release(transactionID uuid.UUID) error {
+	reservations, err := r.claimActive(transactionID, ReservationReleased)
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		// produk yang dihapus saat reservasinya masih aktif tetap diproses,
+		// kalau tidak transaksinya tidak pernah bisa selesai
+		if err := r.db.Unscoped().Model(&products.Product{}).
+			Where("id = ?", reservation.ProductID).
+			Update("reserved_quantity", gorm.Expr("GREATEST(reserved_quantity - ?, 0)", reservation.Quantity)).
+			Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Convert mengubah reservasi aktif menjadi penjualan: stok fisik dan
+// reserved_quantity sama-sama berkurang lalu dicatat sebagai movement SALE.
+// Jumlah reservasi yang dikonversi dikembalikan supaya transaksi lama
+// (dibuat sebelum ada reservasi) bisa ditangani terpisah
+func (r *stockReservationRepository) Convert(transactionID uuid.UUID) (int, error) {
+	reservations, err := r.claimActive(transactionID, ReservationConverted)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, reservation := range reservations {
+		result := r.db.Unscoped().Model(&products.Product{}).
+			Where("id = ? AND quantity >= ? AND reserved_quantity >= ?", reservation.ProductID, reservation.Quantity, reservation.Quantity).
+			Updates(map[string]interface{}{
+				"quantity":          gorm.Expr("quantity - ?", reservation.Quantity),
+				"reserved_quantity": gorm.Expr("reserved_quantity - ?", reservation.Quantity),
+			})
+
+		if result.Error != nil {
+			return 0, result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return 0, errors.New("reserved stock is no longer available")
+		}
+
+		movement := &StockMovement{
+			ProductID:     reservation.ProductID,
+			Type:          StockSale,
+			Quantity:      reservation.Quantity,
+			ReferenceID:   &transactionID,
+			ReferenceType: referenceTypeTransaction,
+		}
+
+		if err := r.db.Create(movement).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	return len(reservations), nil
+}
+
+// claimActive memindahkan reservasi ACTIVE ke status baru dan hanya
+// mengembalikan baris yang benar-benar berubah
+func (r *stockReservationRepository) claimActive(transactionID uuid.UUID, status StockReservationStatus) ([]StockReservation, error) {
+	var reservations []StockReservation
+
+	result := r.db.Model(&reservations).
+		Clauses(clause.Returning{}).
+		Where("transaction_id = ? AND status = ?", transactionID, ReservationActive).
+		Update("status", status)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,116 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+
+	"go-fiber-api/internal/features/products"
+	"go-fiber-api/internal/util/testdb"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func newReservationTest(t *testing.T, quantity int) (*gorm.DB, StockReservationRepository, products.Product) {
+	t.Helper()
+
+	db := testdb.Open(t, &products.Product{}, &StockMovement{}, &StockReservation{})
+
+	product := products.Product{
+		MerchantID:      uuid.New(),
+		Name:            "Test Product",
+		Price:           decimal.NewFromInt(10000),
+		Quantity:        quantity,
+		ProductPhotoUrl: "http://localhost/photo.png",
+	}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	return db, NewStockReservationRepository(db), product
+}
+
+func loadProduct(t *testing.T, db *gorm.DB, id uuid.UUID) products.Product {
+	t.Helper()
+
+	var product products.Product
+	if err := db.Unscoped().First(&product, "id = ?", id).Error; err != nil {
+		t.Fatalf("find product: %v", err)
+	}
+
+	return product
+}
+
+func TestReserveRespectsAvailableStock(t *testing.T) {
+	db, repo, product := newReservationTest(t, 3)
+
+	if err := repo.Reserve(uuid.New(), product.ID, 2); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	if err := repo.Reserve(uuid.New(), product.ID, 2); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Reserve over available stock error = %v, want %v", err, ErrInsufficientStock)
+	}
+
+	if got := loadProduct(t, db, product.ID); got.Quantity != 3 || got.ReservedQuantity != 2 {
+		t.Fatalf("quantity/reserved = %d/%d, want 3/2", got.Quantity, got.ReservedQuantity)
+	}
+}
+
+func TestConvertAndReleaseAreIdempotent(t *testing.T) {
+	db, repo, product := newReservationTest(t, 5)
+	paid, failed := uuid.New(), uuid.New()
+
+	for _, id := range []uuid.UUID{paid, failed} {
+		if err := repo.Reserve(id, product.ID, 2); err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.Convert(paid); err != nil {
+			t.Fatalf("Convert #%d: %v", i+1, err)
+		}
+
+		if err := repo.Release(failed); err != nil {
+			t.Fatalf("Release #%d: %v", i+1, err)
+		}
+	}
+
+	if got := loadProduct(t, db, product.ID); got.Quantity != 3 || got.ReservedQuantity != 0 {
+		t.Fatalf("quantity/reserved = %d/%d, want 3/0", got.Quantity, got.ReservedQuantity)
+	}
+}
+
+func TestReservationOfDeletedProduct(t *testing.T) {
+	db, repo, product := newReservationTest(t, 5)
+	paid, failed := uuid.New(), uuid.New()
+
+	for _, id := range []uuid.UUID{paid, failed} {
+		if err := repo.Reserve(id, product.ID, 2); err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+	}
+
+	if err := db.Delete(&products.Product{}, "id = ?", product.ID).Error; err != nil {
+		t.Fatalf("delete product: %v", err)
+	}
+
+	converted, err := repo.Convert(paid)
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	if converted != 1 {
+		t.Fatalf("converted = %d, want 1", converted)
+	}
+
+	if err := repo.Release(failed); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	if got := loadProduct(t, db, product.ID); got.Quantity != 3 || got.ReservedQuantity != 0 {
+		t.Fatalf("quantity/reserved = %d/%d, want 3/0", got.Quantity, got.ReservedQuantity)
+	}
+}
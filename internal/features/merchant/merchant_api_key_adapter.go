@@ -0,0 +1,29 @@
+package merchant
+
+import (
+	"go-fiber-api/internal/middleware"
+)
+
+type APIKeyValidatorAdapter struct {
+	service MerchantAPIKeyService
+}
+
+func NewAPIKeyValidatorAdapter(service MerchantAPIKeyService) middleware.APIKeyValidator {
+	return &APIKeyValidatorAdapter{
+		service: service,
+	}
+}
+
+func (a *APIKeyValidatorAdapter) ValidateAPIKey(key string) (*middleware.APIKeyPrincipal, error) {
+	apiKey, err := a.service.ValidateAPIKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &middleware.APIKeyPrincipal{
+		KeyID:      apiKey.ID,
+		MerchantID: apiKey.MerchantID,
+		CreatedBy:  apiKey.CreatedBy,
+		Scopes:     apiKey.Scopes,
+	}, nil
+}
@@ -0,0 +1,28 @@
+package merchant
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerchantAPIKey dipakai integrasi (POS, ERP) untuk memanggil API atas nama
+// merchant. Yang disimpan hanya hash key, key mentah ditampilkan sekali saat dibuat
+type MerchantAPIKey struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name       string    `gorm:"type:varchar(100);not null"`
+	Prefix     string    `gorm:"type:varchar(20);not null"`
+	KeyHash    string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Scopes     []string  `gorm:"type:jsonb;not null;serializer:json"`
+	CreatedBy  uuid.UUID `gorm:"type:uuid;not null"`
+
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time `gorm:"index"`
+
+	// Relations
+	Merchant Merchant `gorm:"foreignKey:MerchantID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
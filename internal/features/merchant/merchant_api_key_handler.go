@@ -0,0 +1,92 @@
+package merchant
+
+import (
+	"errors"
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type MerchantAPIKeyHandler interface {
+	CreateAPIKey(c *fiber.Ctx) error
+	GetAPIKeys(c *fiber.Ctx) error
+	RevokeAPIKey(c *fiber.Ctx) error
+}
+
+type merchantAPIKeyHandler struct {
+	apiKeyService MerchantAPIKeyService
+}
+
+func NewMerchantAPIKeyHandler(service MerchantAPIKeyService) MerchantAPIKeyHandler {
+	return &merchantAPIKeyHandler{
+		apiKeyService: service,
+	}
+}
+
+func (h *merchantAPIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id format")
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	creatorID := c.Locals("user_id").(*token.CustomClaims).UserID
+
+	apiKey, err := h.apiKeyService.CreateAPIKey(merchantID, creatorID, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAPIKeyScope) {
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to create api key")
+	}
+
+	return response.SuccessWithStatus(c, fiber.StatusCreated, "api key created, store the key now as it will not be shown again", apiKey)
+}
+
+func (h *merchantAPIKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id format")
+	}
+
+	apiKeys, err := h.apiKeyService.GetAPIKeys(merchantID)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to retrieve api keys")
+	}
+
+	return response.Success(c, "api keys retrieved", apiKeys)
+}
+
+func (h *merchantAPIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id format")
+	}
+
+	keyID, err := uuid.Parse(c.Params("key_id"))
+	if err != nil || keyID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid api key id format")
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(merchantID, keyID); err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return response.Fail(c, fiber.StatusNotFound, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to revoke api key")
+	}
+
+	return response.SuccessNoData(c, "api key revoked")
+}
@@ -0,0 +1,85 @@
+package merchant
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MerchantAPIKeyRepository interface {
+	Create(apiKey *MerchantAPIKey) error
+	FindByHash(hash string) (*MerchantAPIKey, error)
+	FindByID(id uuid.UUID) (*MerchantAPIKey, error)
+	GetByMerchantID(merchantID uuid.UUID) ([]MerchantAPIKey, error)
+	Revoke(id uuid.UUID) error
+	TouchLastUsed(id uuid.UUID) error
+}
+
+type merchantAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewMerchantAPIKeyRepository(db *gorm.DB) MerchantAPIKeyRepository {
+	return &merchantAPIKeyRepository{
+		db: db,
+	}
+}
+
+func (r *merchantAPIKeyRepository) Create(apiKey *MerchantAPIKey) error {
+	return r.db.Create(apiKey).Error
+}
+
+func (r *merchantAPIKeyRepository) FindByHash(hash string) (*MerchantAPIKey, error) {
+	var apiKey MerchantAPIKey
+
+	err := r.db.Where("key_hash = ?", hash).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *merchantAPIKeyRepository) FindByID(id uuid.UUID) (*MerchantAPIKey, error) {
+	var apiKey MerchantAPIKey
+
+	err := r.db.Where("id = ?", id).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *merchantAPIKeyRepository) GetByMerchantID(merchantID uuid.UUID) ([]MerchantAPIKey, error) {
+	var apiKeys []MerchantAPIKey
+
+	err := r.db.
+		Where("merchant_id = ?", merchantID).
+		Order("created_at DESC").
+		Find(&apiKeys).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r *merchantAPIKeyRepository) Revoke(id uuid.UUID) error {
+	return r.db.
+		Model(&MerchantAPIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now().UTC()).
+		Error
+}
+
+func (r *merchantAPIKeyRepository) TouchLastUsed(id uuid.UUID) error {
+	return r.db.
+		Model(&MerchantAPIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", time.Now().UTC()).
+		Error
+}
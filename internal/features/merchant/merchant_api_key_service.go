@@ -0,0 +1,169 @@
+package merchant
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/util/permission"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// last_used_at cukup di-update sesekali, tidak perlu tiap request
+const apiKeyTouchInterval = time.Minute
+
+var (
+	ErrInvalidAPIKeyScope = errors.New("invalid api key scope")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrAPIKeyRevoked      = errors.New("api key has been revoked")
+	// ErrAPIKeyCreatorInactive: pembuat key sudah bukan member aktif merchant
+	ErrAPIKeyCreatorInactive = errors.New("api key creator is no longer a member of the merchant")
+)
+
+type MerchantAPIKeyService interface {
+	CreateAPIKey(merchantID uuid.UUID, creatorID uuid.UUID, req *CreateAPIKeyRequest) (*CreatedAPIKeyDTO, error)
+	GetAPIKeys(merchantID uuid.UUID) ([]MerchantAPIKeyDTO, error)
+	RevokeAPIKey(merchantID uuid.UUID, keyID uuid.UUID) error
+	ValidateAPIKey(plain string) (*MerchantAPIKey, error)
+}
+
+type merchantAPIKeyService struct {
+	apiKeyRepository MerchantAPIKeyRepository
+	memberRepository MerchantMemberRepository
+}
+
+func NewMerchantAPIKeyService(apiKeyRepo MerchantAPIKeyRepository, memberRepo MerchantMemberRepository) MerchantAPIKeyService {
+	return &merchantAPIKeyService{
+		apiKeyRepository: apiKeyRepo,
+		memberRepository: memberRepo,
+	}
+}
+
+func toMerchantAPIKeyDTO(k *MerchantAPIKey) MerchantAPIKeyDTO {
+	return MerchantAPIKeyDTO{
+		ID:         k.ID,
+		MerchantID: k.MerchantID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedBy:  k.CreatedBy,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func (s *merchantAPIKeyService) CreateAPIKey(
+	merchantID uuid.UUID,
+	creatorID uuid.UUID,
+	req *CreateAPIKeyRequest,
+) (*CreatedAPIKeyDTO, error) {
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !permission.IsValidAPIKeyScope(scope) {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		if !permission.Has(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	plain, hash, err := token.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &MerchantAPIKey{
+		MerchantID: merchantID,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     plain[:len(token.APIKeyPrefix)+6],
+		KeyHash:    hash,
+		Scopes:     scopes,
+		CreatedBy:  creatorID,
+	}
+
+	if err := s.apiKeyRepository.Create(apiKey); err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKeyDTO{
+		MerchantAPIKeyDTO: toMerchantAPIKeyDTO(apiKey),
+		Key:               plain,
+	}, nil
+}
+
+func (s *merchantAPIKeyService) GetAPIKeys(merchantID uuid.UUID) ([]MerchantAPIKeyDTO, error) {
+	apiKeys, err := s.apiKeyRepository.GetByMerchantID(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MerchantAPIKeyDTO, 0, len(apiKeys))
+	for i := range apiKeys {
+		result = append(result, toMerchantAPIKeyDTO(&apiKeys[i]))
+	}
+
+	return result, nil
+}
+
+func (s *merchantAPIKeyService) RevokeAPIKey(merchantID uuid.UUID, keyID uuid.UUID) error {
+	apiKey, err := s.apiKeyRepository.FindByID(keyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	if apiKey.MerchantID != merchantID {
+		return ErrAPIKeyNotFound
+	}
+
+	return s.apiKeyRepository.Revoke(apiKey.ID)
+}
+
+func (s *merchantAPIKeyService) ValidateAPIKey(plain string) (*MerchantAPIKey, error) {
+	apiKey, err := s.apiKeyRepository.FindByHash(token.HashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	// key tidak boleh melebihi hak pembuatnya saat ini: member yang
+	// dikeluarkan kehilangan semua key-nya, member yang diturunkan role-nya
+	// hanya menyisakan scope yang masih dia punya
+	member, err := s.memberRepository.FindActiveMembership(apiKey.MerchantID, apiKey.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if member == nil {
+		return nil, ErrAPIKeyCreatorInactive
+	}
+
+	granted := permission.ForMerchantRole(member.Role)
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		if permission.Has(granted, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	apiKey.Scopes = scopes
+
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeyRepository.TouchLastUsed(apiKey.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,170 @@
+package merchant
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-api/internal/util/permission"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type memoryAPIKeyRepository struct {
+	MerchantAPIKeyRepository
+	keys map[string]*MerchantAPIKey
+}
+
+func (r *memoryAPIKeyRepository) Create(apiKey *MerchantAPIKey) error {
+	apiKey.ID = uuid.New()
+	r.keys[apiKey.KeyHash] = apiKey
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) FindByHash(hash string) (*MerchantAPIKey, error) {
+	apiKey, ok := r.keys[hash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	copied := *apiKey
+	return &copied, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(id uuid.UUID) error {
+	now := time.Now()
+	for _, apiKey := range r.keys {
+		if apiKey.ID == id {
+			apiKey.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) FindByID(id uuid.UUID) (*MerchantAPIKey, error) {
+	for _, apiKey := range r.keys {
+		if apiKey.ID == id {
+			return apiKey, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAPIKeyRepository) TouchLastUsed(id uuid.UUID) error {
+	return nil
+}
+
+type memoryMemberRepository struct {
+	MerchantMemberRepository
+	members []MerchantMember
+}
+
+func (r *memoryMemberRepository) FindActiveMembership(merchantID uuid.UUID, userID uuid.UUID) (*MerchantMember, error) {
+	for i := range r.members {
+		m := &r.members[i]
+		if m.MerchantID == merchantID && m.UserID != nil && *m.UserID == userID && m.Status == MemberStatusActive {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+type apiKeyTest struct {
+	service    MerchantAPIKeyService
+	keys       *memoryAPIKeyRepository
+	members    *memoryMemberRepository
+	merchantID uuid.UUID
+	creatorID  uuid.UUID
+}
+
+func newAPIKeyTest(role permission.MerchantRole) *apiKeyTest {
+	merchantID, creatorID := uuid.New(), uuid.New()
+
+	keys := &memoryAPIKeyRepository{keys: map[string]*MerchantAPIKey{}}
+	members := &memoryMemberRepository{members: []MerchantMember{{
+		MerchantID: merchantID,
+		UserID:     &creatorID,
+		Role:       role,
+		Status:     MemberStatusActive,
+	}}}
+
+	return &apiKeyTest{
+		service:    NewMerchantAPIKeyService(keys, members),
+		keys:       keys,
+		members:    members,
+		merchantID: merchantID,
+		creatorID:  creatorID,
+	}
+}
+
+func (a *apiKeyTest) create(t *testing.T, scopes ...string) *CreatedAPIKeyDTO {
+	t.Helper()
+
+	created, err := a.service.CreateAPIKey(a.merchantID, a.creatorID, &CreateAPIKeyRequest{Name: "pos", Scopes: scopes})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	return created
+}
+
+func TestCreateAPIKeyRejectsUnscopedPermissions(t *testing.T) {
+	a := newAPIKeyTest(permission.MerchantRoleOwner)
+
+	for _, scope := range []string{permission.MerchantMembersManage, permission.MerchantAPIKeysManage, permission.All, "unknown"} {
+		_, err := a.service.CreateAPIKey(a.merchantID, a.creatorID, &CreateAPIKeyRequest{Name: "pos", Scopes: []string{scope}})
+		if !errors.Is(err, ErrInvalidAPIKeyScope) {
+			t.Errorf("scope %q: CreateAPIKey error = %v, want %v", scope, err, ErrInvalidAPIKeyScope)
+		}
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	a := newAPIKeyTest(permission.MerchantRoleOwner)
+	created := a.create(t, permission.MerchantProductsRead, permission.MerchantProductsWrite, permission.MerchantProductsRead)
+
+	apiKey, err := a.service.ValidateAPIKey(created.Key)
+	if err != nil {
+		t.Fatalf("ValidateAPIKey: %v", err)
+	}
+
+	if len(apiKey.Scopes) != 2 || !permission.Has(apiKey.Scopes, permission.MerchantProductsWrite) {
+		t.Fatalf("scopes = %v, want products read and write", apiKey.Scopes)
+	}
+
+	if _, err := a.service.ValidateAPIKey(created.Key + "x"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("unknown key error = %v, want %v", err, ErrAPIKeyNotFound)
+	}
+
+	if err := a.service.RevokeAPIKey(a.merchantID, created.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+
+	if _, err := a.service.ValidateAPIKey(created.Key); !errors.Is(err, ErrAPIKeyRevoked) {
+		t.Fatalf("revoked key error = %v, want %v", err, ErrAPIKeyRevoked)
+	}
+}
+
+func TestValidateAPIKeyFollowsCreatorMembership(t *testing.T) {
+	a := newAPIKeyTest(permission.MerchantRoleManager)
+	created := a.create(t, permission.MerchantProductsWrite, permission.MerchantTransactionsRead)
+
+	// diturunkan jadi kasir: scope yang tidak dimiliki kasir hilang
+	a.members.members[0].Role = permission.MerchantRoleCashier
+
+	apiKey, err := a.service.ValidateAPIKey(created.Key)
+	if err != nil {
+		t.Fatalf("ValidateAPIKey: %v", err)
+	}
+
+	if permission.Has(apiKey.Scopes, permission.MerchantProductsWrite) || !permission.Has(apiKey.Scopes, permission.MerchantTransactionsRead) {
+		t.Fatalf("scopes after downgrade = %v, want only %s", apiKey.Scopes, permission.MerchantTransactionsRead)
+	}
+
+	a.members.members[0].Status = MemberStatusRemoved
+
+	if _, err := a.service.ValidateAPIKey(created.Key); !errors.Is(err, ErrAPIKeyCreatorInactive) {
+		t.Fatalf("removed creator error = %v, want %v", err, ErrAPIKeyCreatorInactive)
+	}
+}
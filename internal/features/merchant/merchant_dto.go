@@ -43,4 +43,5 @@ type MerchantSummary struct {
 	Name            string    `json:"name"`
 	Description     string    `json:"description"`
 	ProfilePhotoUrl string    `json:"profile_photo_url"`
+	Role            string    `json:"role,omitempty"`
 }
@@ -25,11 +25,13 @@ type MerchantHandler interface {
 
 type merchantHandler struct {
 	merchantService MerchantService
+	storage         upload.Storage
 }
 
-func NewMerchantHandler(service MerchantService) MerchantHandler {
+func NewMerchantHandler(service MerchantService, storage upload.Storage) MerchantHandler {
 	return &merchantHandler{
 		merchantService: service,
+		storage:         storage,
 	}
 }
 
@@ -50,7 +52,7 @@ func (h *merchantHandler) AddMerchant(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusBadRequest, "profile_photo is required")
 	}
 
-	profileResult, err := upload.UploadToSupabaseStorage(
+	profileResult, err := h.storage.UploadToSupabaseStorage(
 		ctx,
 		profileFiles[0],
 		"profiles",
@@ -67,7 +69,7 @@ func (h *merchantHandler) AddMerchant(c *fiber.Ctx) error {
 	var bannerURL string
 
 	if len(bannerFiles) > 0 {
-		bannerResult, err := upload.UploadToSupabaseStorage(
+		bannerResult, err := h.storage.UploadToSupabaseStorage(
 			ctx,
 			bannerFiles[0],
 			"banners",
@@ -89,7 +91,7 @@ func (h *merchantHandler) AddMerchant(c *fiber.Ctx) error {
 	galleryURLs := make([]string, 0, len(galleryFiles))
 
 	for _, fileHeader := range galleryFiles {
-		result, err := upload.UploadToSupabaseStorage(
+		result, err := h.storage.UploadToSupabaseStorage(
 			ctx,
 			fileHeader,
 			"galleries",
@@ -185,17 +187,17 @@ func (h *merchantHandler) GetAllMerchant(c *fiber.Ctx) error {
 }
 
 func (h *merchantHandler) GetMyMerchantDashboard(c *fiber.Ctx) error {
-	claims := c.Locals("user_id").(*token.CustomClaims)
-	userID := claims.UserID
-
-	merchant, err := h.merchantService.GetMyMerchant(userID)
+	// akses member ke merchant ini sudah dicek di middleware.RequirePermission
+	merchantID, err := uuid.Parse(c.Params("id"))
 
 	if err != nil {
-		return response.Fail(c, fiber.StatusInternalServerError, "failed to retrieve merchant")
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id format")
 	}
 
-	if merchant == nil {
-		return response.Success[any](c, "merchant not found", nil)
+	merchant, err := h.merchantService.GetMerchantById(merchantID)
+
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to retrieve merchant")
 	}
 
 	return response.Success(c, "merchant retrieved", merchant)
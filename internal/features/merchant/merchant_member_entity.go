@@ -0,0 +1,39 @@
+package merchant
+
+import (
+	"go-fiber-api/internal/util/permission"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MemberStatus string
+
+const (
+	MemberStatusInvited MemberStatus = "INVITED"
+	MemberStatusActive  MemberStatus = "ACTIVE"
+	MemberStatusRemoved MemberStatus = "REMOVED"
+)
+
+type MerchantMember struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_merchant_member_email"`
+	// UserID masih kosong selama undangan belum diterima
+	UserID *uuid.UUID `gorm:"type:uuid;index"`
+	Email  string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_merchant_member_email"`
+
+	Role   permission.MerchantRole `gorm:"type:varchar(30);not null"`
+	Status MemberStatus            `gorm:"type:varchar(20);not null;default:'INVITED'"`
+
+	InvitationTokenHash string `gorm:"type:varchar(64);index"`
+	InvitationExpiresAt *time.Time
+	InvitedBy           *uuid.UUID `gorm:"type:uuid"`
+	AcceptedAt          *time.Time
+	RemovedAt           *time.Time
+
+	// Relations
+	Merchant Merchant `gorm:"foreignKey:MerchantID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
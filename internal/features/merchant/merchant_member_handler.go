@@ -0,0 +1,129 @@
+package merchant
+
+import (
+	"errors"
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type MerchantMemberHandler interface {
+	InviteMember(c *fiber.Ctx) error
+	AcceptInvitation(c *fiber.Ctx) error
+	RemoveMember(c *fiber.Ctx) error
+	GetMembers(c *fiber.Ctx) error
+}
+
+type merchantMemberHandler struct {
+	memberService MerchantMemberService
+}
+
+func NewMerchantMemberHandler(service MerchantMemberService) MerchantMemberHandler {
+	return &merchantMemberHandler{
+		memberService: service,
+	}
+}
+
+func (h *merchantMemberHandler) InviteMember(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id format")
+	}
+
+	var req InviteMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	inviterID := c.Locals("user_id").(*token.CustomClaims).UserID
+
+	member, err := h.memberService.InviteMember(merchantID, inviterID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAlreadyMember):
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case errors.Is(err, ErrInvalidMemberRole):
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to invite member")
+	}
+
+	return response.SuccessWithStatus(c, fiber.StatusCreated, "invitation sent", member)
+}
+
+func (h *merchantMemberHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	userID := c.Locals("user_id").(*token.CustomClaims).UserID
+
+	member, err := h.memberService.AcceptInvitation(userID, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvitationNotFound):
+			return response.Fail(c, fiber.StatusNotFound, err.Error())
+		case errors.Is(err, ErrInvitationExpired):
+			return response.Fail(c, fiber.StatusGone, err.Error())
+		case errors.Is(err, ErrInvitationWrongAccount):
+			return response.Fail(c, fiber.StatusForbidden, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to accept invitation")
+	}
+
+	return response.Success(c, "invitation accepted", member)
+}
+
+func (h *merchantMemberHandler) RemoveMember(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id format")
+	}
+
+	memberID, err := uuid.Parse(c.Params("member_id"))
+	if err != nil || memberID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid member id format")
+	}
+
+	if err := h.memberService.RemoveMember(merchantID, memberID); err != nil {
+		switch {
+		case errors.Is(err, ErrMemberNotFound):
+			return response.Fail(c, fiber.StatusNotFound, err.Error())
+		case errors.Is(err, ErrCannotRemoveOwner):
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to remove member")
+	}
+
+	return response.SuccessNoData(c, "member removed")
+}
+
+func (h *merchantMemberHandler) GetMembers(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("id"))
+	if err != nil || merchantID == uuid.Nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id format")
+	}
+
+	members, err := h.memberService.GetMembers(merchantID)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to retrieve members")
+	}
+
+	return response.Success(c, "members retrieved", members)
+}
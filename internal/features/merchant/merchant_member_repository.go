@@ -0,0 +1,133 @@
+package merchant
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MerchantMemberRepository interface {
+	Create(member *MerchantMember) error
+	Save(member *MerchantMember) error
+	FindByID(id uuid.UUID) (*MerchantMember, error)
+	FindByMerchantAndEmail(merchantID uuid.UUID, email string) (*MerchantMember, error)
+	FindByInvitationTokenHash(hash string) (*MerchantMember, error)
+	FindActiveMembership(merchantID uuid.UUID, userID uuid.UUID) (*MerchantMember, error)
+	GetMembersByMerchantID(merchantID uuid.UUID) ([]MerchantMember, error)
+	MarkRemoved(id uuid.UUID) error
+}
+
+type merchantMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewMerchantMemberRepository(db *gorm.DB) MerchantMemberRepository {
+	return &merchantMemberRepository{
+		db: db,
+	}
+}
+
+func (r *merchantMemberRepository) Create(member *MerchantMember) error {
+	return r.db.Create(member).Error
+}
+
+func (r *merchantMemberRepository) Save(member *MerchantMember) error {
+	return r.db.Save(member).Error
+}
+
+func (r *merchantMemberRepository) FindByID(id uuid.UUID) (*MerchantMember, error) {
+	var member MerchantMember
+
+	err := r.db.Where("id = ?", id).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *merchantMemberRepository) FindByMerchantAndEmail(merchantID uuid.UUID, email string) (*MerchantMember, error) {
+	var member MerchantMember
+
+	err := r.db.
+		Where("merchant_id = ? AND LOWER(email) = LOWER(?)", merchantID, email).
+		Take(&member).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *merchantMemberRepository) FindByInvitationTokenHash(hash string) (*MerchantMember, error) {
+	var member MerchantMember
+
+	err := r.db.
+		Where("invitation_token_hash = ? AND status = ?", hash, MemberStatusInvited).
+		First(&member).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// FindActiveMembership mengembalikan nil tanpa error kalau user bukan member aktif
+func (r *merchantMemberRepository) FindActiveMembership(merchantID uuid.UUID, userID uuid.UUID) (*MerchantMember, error) {
+	var member MerchantMember
+
+	err := r.db.
+		Where("merchant_id = ? AND user_id = ? AND status = ?", merchantID, userID, MemberStatusActive).
+		Take(&member).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *merchantMemberRepository) GetMembersByMerchantID(merchantID uuid.UUID) ([]MerchantMember, error) {
+	var members []MerchantMember
+
+	err := r.db.
+		Where("merchant_id = ? AND status <> ?", merchantID, MemberStatusRemoved).
+		Order("created_at ASC").
+		Find(&members).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *merchantMemberRepository) MarkRemoved(id uuid.UUID) error {
+	updates := map[string]interface{}{
+		"status":                MemberStatusRemoved,
+		"removed_at":            time.Now().UTC(),
+		"invitation_token_hash": "",
+	}
+
+	return r.db.
+		Model(&MerchantMember{}).
+		Where("id = ?", id).
+		Updates(updates).
+		Error
+}
@@ -0,0 +1,215 @@
+package merchant
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/util/mailer"
+	"go-fiber-api/internal/util/permission"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrAlreadyMember          = errors.New("user is already a member of this merchant")
+	ErrInvalidMemberRole      = errors.New("invalid member role")
+	ErrInvitationNotFound     = errors.New("invitation not found")
+	ErrInvitationExpired      = errors.New("invitation has expired")
+	ErrInvitationWrongAccount = errors.New("invitation was sent to a different email")
+	ErrMemberNotFound         = errors.New("member not found")
+	ErrCannotRemoveOwner      = errors.New("merchant owner cannot be removed")
+)
+
+type MerchantMemberService interface {
+	InviteMember(merchantID uuid.UUID, inviterID uuid.UUID, req *InviteMemberRequest) (*MerchantMemberDTO, error)
+	AcceptInvitation(userID uuid.UUID, invitationToken string) (*MerchantMemberDTO, error)
+	RemoveMember(merchantID uuid.UUID, memberID uuid.UUID) error
+	GetMembers(merchantID uuid.UUID) ([]MerchantMemberDTO, error)
+}
+
+type merchantMemberService struct {
+	memberRepository MerchantMemberRepository
+	userService      UserServiceContract
+	mailer           mailer.Mailer
+	appBaseURL       string
+}
+
+func NewMerchantMemberService(
+	memberRepo MerchantMemberRepository,
+	userService UserServiceContract,
+	mail mailer.Mailer,
+	appBaseURL string,
+) MerchantMemberService {
+	return &merchantMemberService{
+		memberRepository: memberRepo,
+		userService:      userService,
+		mailer:           mail,
+		appBaseURL:       strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+func toMerchantMemberDTO(m *MerchantMember) MerchantMemberDTO {
+	return MerchantMemberDTO{
+		ID:         m.ID,
+		MerchantID: m.MerchantID,
+		UserID:     m.UserID,
+		Email:      m.Email,
+		Role:       string(m.Role),
+		Status:     string(m.Status),
+		AcceptedAt: m.AcceptedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func (s *merchantMemberService) InviteMember(
+	merchantID uuid.UUID,
+	inviterID uuid.UUID,
+	req *InviteMemberRequest,
+) (*MerchantMemberDTO, error) {
+	role := permission.MerchantRole(req.Role)
+	if !permission.IsValidMerchantRole(role) || role == permission.MerchantRoleOwner {
+		return nil, ErrInvalidMemberRole
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	member, err := s.memberRepository.FindByMerchantAndEmail(merchantID, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if member != nil && member.Status == MemberStatusActive {
+		return nil, ErrAlreadyMember
+	}
+
+	plain, hash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().UTC().Add(invitationTTL)
+
+	// undangan lama (atau member yang pernah dihapus) dipakai ulang
+	// karena email unik per merchant
+	if member != nil {
+		member.UserID = nil
+		member.Role = role
+		member.Status = MemberStatusInvited
+		member.InvitationTokenHash = hash
+		member.InvitationExpiresAt = &expiresAt
+		member.InvitedBy = &inviterID
+		member.AcceptedAt = nil
+		member.RemovedAt = nil
+
+		err = s.memberRepository.Save(member)
+	} else {
+		member = &MerchantMember{
+			MerchantID:          merchantID,
+			Email:               email,
+			Role:                role,
+			Status:              MemberStatusInvited,
+			InvitationTokenHash: hash,
+			InvitationExpiresAt: &expiresAt,
+			InvitedBy:           &inviterID,
+		}
+
+		err = s.memberRepository.Create(member)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	link := fmt.Sprintf("%s/merchant/invitations/accept?token=%s", s.appBaseURL, url.QueryEscape(plain))
+	mailer.SendAsync(s.mailer, mailer.Message{
+		To:      email,
+		Subject: "You have been invited to join a merchant",
+		Body: fmt.Sprintf(
+			"You have been invited to join a merchant as %s.\n\nLog in with this email address and open the link below within %d days to accept:\n%s",
+			role,
+			int(invitationTTL.Hours()/24),
+			link,
+		),
+	})
+
+	result := toMerchantMemberDTO(member)
+	return &result, nil
+}
+
+func (s *merchantMemberService) AcceptInvitation(userID uuid.UUID, invitationToken string) (*MerchantMemberDTO, error) {
+	member, err := s.memberRepository.FindByInvitationTokenHash(token.HashToken(invitationToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if member.InvitationExpiresAt == nil || now.After(*member.InvitationExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(user.Email, member.Email) {
+		return nil, ErrInvitationWrongAccount
+	}
+
+	member.UserID = &userID
+	member.Status = MemberStatusActive
+	member.AcceptedAt = &now
+	member.InvitationTokenHash = ""
+	member.InvitationExpiresAt = nil
+
+	if err := s.memberRepository.Save(member); err != nil {
+		return nil, err
+	}
+
+	result := toMerchantMemberDTO(member)
+	return &result, nil
+}
+
+func (s *merchantMemberService) RemoveMember(merchantID uuid.UUID, memberID uuid.UUID) error {
+	member, err := s.memberRepository.FindByID(memberID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+
+	if member.MerchantID != merchantID || member.Status == MemberStatusRemoved {
+		return ErrMemberNotFound
+	}
+
+	if member.Role == permission.MerchantRoleOwner {
+		return ErrCannotRemoveOwner
+	}
+
+	return s.memberRepository.MarkRemoved(member.ID)
+}
+
+func (s *merchantMemberService) GetMembers(merchantID uuid.UUID) ([]MerchantMemberDTO, error) {
+	members, err := s.memberRepository.GetMembersByMerchantID(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MerchantMemberDTO, 0, len(members))
+	for i := range members {
+		result = append(result, toMerchantMemberDTO(&members[i]))
+	}
+
+	return result, nil
+}
@@ -2,6 +2,8 @@ package merchant
 
 import (
 	"errors"
+	"go-fiber-api/internal/util/permission"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -27,7 +29,40 @@ func NewMerchantRepository(db *gorm.DB) MerchantRepository {
 }
 
 func (mr *merchantRepository) CreateMerchant(merchant *Merchant) (*Merchant, error) {
-	if err := mr.db.Create(merchant).Error; err != nil {
+	err := mr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(merchant).Error; err != nil {
+			return err
+		}
+
+		var email string
+		if err := tx.Table("users").Select("email").Where("id = ?", merchant.UserID).Scan(&email).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		userID := merchant.UserID
+		owner := &MerchantMember{
+			MerchantID: merchant.ID,
+			UserID:     &userID,
+			Email:      email,
+			Role:       permission.MerchantRoleOwner,
+			Status:     MemberStatusActive,
+			AcceptedAt: &now,
+		}
+
+		if err := tx.Create(owner).Error; err != nil {
+			return err
+		}
+
+		// customer yang membuat merchant naik role jadi merchant owner
+		return tx.
+			Table("users").
+			Where("id = ? AND role = ?", merchant.UserID, permission.RoleCustomer).
+			Update("role", permission.RoleMerchantOwner).
+			Error
+	})
+
+	if err != nil {
 		return nil, err
 	}
 	return merchant, nil
@@ -82,10 +117,12 @@ func (mr *merchantRepository) GetMyMerchantsSummary(
 
 	var merchant []MerchantSummary
 
+	// termasuk merchant tempat user menjadi staff
 	err := mr.db.
 		Table("merchants").
-		Select("id, user_id, name, description, profile_photo_url").
-		Where("user_id = ?", userID).
+		Select("merchants.id, merchants.user_id, merchants.name, merchants.description, merchants.profile_photo_url, merchant_members.role").
+		Joins("JOIN merchant_members ON merchant_members.merchant_id = merchants.id").
+		Where("merchant_members.user_id = ? AND merchant_members.status = ?", userID, MemberStatusActive).
 		Find(&merchant).
 		Error
 
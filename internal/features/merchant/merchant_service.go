@@ -76,7 +76,7 @@ func (ms *merchantService) GetMerchantById(id uuid.UUID) (*MerchantDTO, error) {
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get merchant by id: %w", err.Error())
+		return nil, fmt.Errorf("failed to get merchant by id: %w", err)
 	}
 
 	return result, err
@@ -149,6 +149,7 @@ func (ms *merchantService) GetMyMerchantsSummary(userID uuid.UUID) ([]MerchantSu
 			Name:            m.Name,
 			Description:     m.Description,
 			ProfilePhotoUrl: m.ProfilePhotoUrl,
+			Role:            m.Role,
 		}
 	}
 
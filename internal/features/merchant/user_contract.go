@@ -0,0 +1,13 @@
+package merchant
+
+import "github.com/google/uuid"
+
+// DTO ringan untuk kebutuhan merchant, di-map dari auth.AuthResponse
+type UserInfo struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+}
+
+type UserServiceContract interface {
+	GetUserByID(id uuid.UUID) (*UserInfo, error)
+}
@@ -0,0 +1,94 @@
+package privacy
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	// harus diisi "DELETE" supaya tidak terhapus tanpa sengaja
+	Confirmation string `json:"confirmation" validate:"required,eq=DELETE"`
+}
+
+type DataExportDTO struct {
+	ID          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"`
+	FileSize    int64      `json:"file_size,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ExportArchive adalah isi data.json di dalam ZIP export
+type ExportArchive struct {
+	ExportedAt   time.Time           `json:"exported_at"`
+	Profile      ExportProfile       `json:"profile"`
+	Sessions     []ExportSession     `json:"sessions"`
+	Transactions []ExportTransaction `json:"transactions"`
+	Follows      []ExportFollow      `json:"follows"`
+	Merchants    []ExportMerchant    `json:"merchants"`
+	Memberships  []ExportMembership  `json:"merchant_memberships"`
+}
+
+type ExportProfile struct {
+	ID               uuid.UUID  `json:"id"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	DisplayName      string     `json:"display_name"`
+	PhoneNumber      string     `json:"phone_number"`
+	AvatarURL        string     `json:"avatar_url"`
+	Locale           string     `json:"locale"`
+	MarketingConsent bool       `json:"marketing_consent"`
+	CreatedAt        *time.Time `json:"created_at"`
+}
+
+type ExportSession struct {
+	Device     string     `json:"device"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ExportTransaction struct {
+	ID          uuid.UUID               `json:"id"`
+	OrderID     string                  `json:"order_id"`
+	MerchantID  uuid.UUID               `json:"merchant_id"`
+	Status      string                  `json:"status"`
+	TotalAmount decimal.Decimal         `json:"total_amount"`
+	PaymentType string                  `json:"payment_type"`
+	Items       []ExportTransactionItem `json:"items"`
+	CreatedAt   time.Time               `json:"created_at"`
+}
+
+type ExportTransactionItem struct {
+	ProductID uuid.UUID       `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	Price     decimal.Decimal `json:"price"`
+	Subtotal  decimal.Decimal `json:"subtotal"`
+}
+
+type ExportFollow struct {
+	MerchantID uuid.UUID `json:"merchant_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ExportMerchant struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+}
+
+type ExportMembership struct {
+	MerchantID uuid.UUID  `json:"merchant_id"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
@@ -0,0 +1,39 @@
+package privacy
+
+import (
+	"time"
+
+	"go-fiber-api/internal/features/auth"
+
+	"github.com/google/uuid"
+)
+
+type ExportStatus string
+
+const (
+	ExportStatusPending    ExportStatus = "PENDING"
+	ExportStatusProcessing ExportStatus = "PROCESSING"
+	ExportStatusReady      ExportStatus = "READY"
+	ExportStatusFailed     ExportStatus = "FAILED"
+)
+
+// DataExport adalah job "export my data" milik user. File ZIP disimpan
+// di DATA_EXPORT_DIR dan hanya bisa diunduh sampai ExpiresAt
+type DataExport struct {
+	ID     uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Status ExportStatus `gorm:"type:varchar(20);not null;default:'PENDING'"`
+
+	FilePath string `gorm:"type:text"`
+	FileSize int64
+	Error    string `gorm:"type:text"`
+
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+
+	// Relations
+	User auth.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
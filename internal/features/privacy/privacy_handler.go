@@ -0,0 +1,129 @@
+package privacy
+
+import (
+	"errors"
+
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PrivacyHandler interface {
+	RequestExport(c *fiber.Ctx) error
+	GetExports(c *fiber.Ctx) error
+	DownloadExport(c *fiber.Ctx) error
+	DeleteAccount(c *fiber.Ctx) error
+}
+
+type privacyHandler struct {
+	privacyService PrivacyService
+	cookies        *token.CookiePolicy
+}
+
+func NewPrivacyHandler(service PrivacyService, cookies *token.CookiePolicy) PrivacyHandler {
+	return &privacyHandler{
+		privacyService: service,
+		cookies:        cookies,
+	}
+}
+
+func (h *privacyHandler) RequestExport(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	export, err := h.privacyService.RequestExport(claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrExportInProgress) {
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		}
+
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to request data export")
+	}
+
+	return response.SuccessWithStatus(c, fiber.StatusAccepted, "data export requested", export)
+}
+
+func (h *privacyHandler) GetExports(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	exports, err := h.privacyService.GetExports(claims.UserID)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, "failed to get data exports")
+	}
+
+	return response.Success(c, "data exports retrieved", exports)
+}
+
+func (h *privacyHandler) DownloadExport(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid export id")
+	}
+
+	path, err := h.privacyService.GetExportFile(claims.UserID, exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrExportNotFound):
+			return response.Fail(c, fiber.StatusNotFound, err.Error())
+		case errors.Is(err, ErrExportNotReady):
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case errors.Is(err, ErrExportExpired):
+			return response.Fail(c, fiber.StatusGone, err.Error())
+		default:
+			return response.Fail(c, fiber.StatusInternalServerError, "failed to get data export")
+		}
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	return c.Download(path, "data-export.zip")
+}
+
+func (h *privacyHandler) DeleteAccount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+	}
+
+	var req DeleteAccountRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "failed to parse request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, fiber.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	if err := h.privacyService.DeleteAccount(claims.UserID, &req); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPassword):
+			return response.Fail(c, fiber.StatusUnauthorized, err.Error())
+		case errors.Is(err, ErrMerchantOwnerDelete):
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case errors.Is(err, ErrAccountDeleted):
+			return response.Fail(c, fiber.StatusGone, err.Error())
+		default:
+			return response.Fail(c, fiber.StatusInternalServerError, "failed to delete account")
+		}
+	}
+
+	h.cookies.ClearAuthTokens(c)
+
+	return response.SuccessNoData(c, "account deleted")
+}
@@ -0,0 +1,279 @@
+package privacy
+
+import (
+	"errors"
+	"time"
+
+	"go-fiber-api/internal/features/auth"
+	"go-fiber-api/internal/features/follow"
+	"go-fiber-api/internal/features/merchant"
+	"go-fiber-api/internal/features/transactions"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PrivacyRepository interface {
+	CreateExport(export *DataExport) error
+	FindExportByID(id uuid.UUID) (*DataExport, error)
+	FindInProgressExport(userID uuid.UUID) (*DataExport, error)
+	FailStaleExports(userID uuid.UUID, staleBefore time.Time) error
+	GetExportsByUserID(userID uuid.UUID) ([]DataExport, error)
+	UpdateExport(id uuid.UUID, updates map[string]interface{}) error
+
+	FindUser(userID uuid.UUID) (*auth.User, error)
+	FindSessions(userID uuid.UUID) ([]auth.Session, error)
+	FindTransactions(userID uuid.UUID) ([]transactions.Transaction, error)
+	FindFollows(userID uuid.UUID) ([]follow.Follow, error)
+	FindOwnedMerchants(userID uuid.UUID) ([]merchant.Merchant, error)
+	FindMemberships(userID uuid.UUID) ([]merchant.MerchantMember, error)
+
+	AnonymizeUser(userID uuid.UUID, anonymizedEmail string, audit *auth.AuditLog) error
+}
+
+type privacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) PrivacyRepository {
+	return &privacyRepository{
+		db: db,
+	}
+}
+
+func (r *privacyRepository) CreateExport(export *DataExport) error {
+	return r.db.Create(export).Error
+}
+
+func (r *privacyRepository) FindExportByID(id uuid.UUID) (*DataExport, error) {
+	var export DataExport
+
+	err := r.db.Where("id = ?", id).First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// FindInProgressExport mengembalikan nil tanpa error kalau tidak ada job yang berjalan
+func (r *privacyRepository) FindInProgressExport(userID uuid.UUID) (*DataExport, error) {
+	var export DataExport
+
+	err := r.db.
+		Where("user_id = ? AND status IN ?", userID, []ExportStatus{ExportStatusPending, ExportStatusProcessing}).
+		Take(&export).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// FailStaleExports menandai job yang tidak bergerak sejak staleBefore
+// sebagai FAILED, biasanya karena proses restart saat export dibangun
+func (r *privacyRepository) FailStaleExports(userID uuid.UUID, staleBefore time.Time) error {
+	return r.db.
+		Model(&DataExport{}).
+		Where("user_id = ? AND status IN ? AND updated_at < ?", userID, []ExportStatus{ExportStatusPending, ExportStatusProcessing}, staleBefore).
+		Updates(map[string]interface{}{
+			"status": ExportStatusFailed,
+			"error":  "export was interrupted",
+		}).
+		Error
+}
+
+func (r *privacyRepository) GetExportsByUserID(userID uuid.UUID) ([]DataExport, error) {
+	var exports []DataExport
+
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&exports).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return exports, nil
+}
+
+func (r *privacyRepository) UpdateExport(id uuid.UUID, updates map[string]interface{}) error {
+	return r.db.
+		Model(&DataExport{}).
+		Where("id = ?", id).
+		Updates(updates).
+		Error
+}
+
+func (r *privacyRepository) FindUser(userID uuid.UUID) (*auth.User, error) {
+	var user auth.User
+
+	err := r.db.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *privacyRepository) FindSessions(userID uuid.UUID) ([]auth.Session, error) {
+	var sessions []auth.Session
+
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *privacyRepository) FindTransactions(userID uuid.UUID) ([]transactions.Transaction, error) {
+	var trx []transactions.Transaction
+
+	err := r.db.
+		Preload("Items").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&trx).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return trx, nil
+}
+
+func (r *privacyRepository) FindFollows(userID uuid.UUID) ([]follow.Follow, error) {
+	var follows []follow.Follow
+
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&follows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return follows, nil
+}
+
+func (r *privacyRepository) FindOwnedMerchants(userID uuid.UUID) ([]merchant.Merchant, error) {
+	var merchants []merchant.Merchant
+
+	err := r.db.Where("user_id = ?", userID).Find(&merchants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return merchants, nil
+}
+
+func (r *privacyRepository) FindMemberships(userID uuid.UUID) ([]merchant.MerchantMember, error) {
+	var members []merchant.MerchantMember
+
+	err := r.db.Where("user_id = ?", userID).Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// AnonymizeUser menghapus data pribadi user dalam satu transaksi database.
+// Row users dan transactions tetap ada untuk keperluan pembukuan
+func (r *privacyRepository) AnonymizeUser(userID uuid.UUID, anonymizedEmail string, audit *auth.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		var user auth.User
+		if err := tx.Select("email").Where("id = ?", userID).Take(&user).Error; err != nil {
+			return err
+		}
+
+		userUpdates := map[string]interface{}{
+			"email":                anonymizedEmail,
+			"password":             "",
+			"pending_email":        "",
+			"display_name":         "",
+			"phone_number":         "",
+			"avatar_url":           "",
+			"marketing_consent":    false,
+			"marketing_consent_at": nil,
+			"email_verified_at":    nil,
+			"verification_sent_at": nil,
+			"anonymized_at":        now,
+		}
+
+		if err := tx.Model(&auth.User{}).Where("id = ?", userID).Updates(userUpdates).Error; err != nil {
+			return err
+		}
+
+		sessionUpdates := map[string]interface{}{
+			"revoked_at":     now,
+			"revoked_reason": auth.SessionRevokedAccountDeleted,
+		}
+
+		if err := tx.Model(&auth.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Updates(sessionUpdates).Error; err != nil {
+			return err
+		}
+
+		// data sesi (IP, user agent) juga termasuk data pribadi
+		if err := tx.Model(&auth.Session{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]interface{}{"ip_address": "", "user_agent": "", "device": ""}).Error; err != nil {
+			return err
+		}
+
+		deletes := []interface{}{
+			&auth.UserIdentity{},
+			&auth.UserTwoFactor{},
+			&auth.RecoveryCode{},
+			&auth.LoginChallenge{},
+			&auth.PasswordResetToken{},
+			&follow.Follow{},
+		}
+
+		for _, model := range deletes {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// audit login gagal sebelum user dikenali hanya menyimpan email
+		if err := tx.Model(&auth.AuditLog{}).
+			Where("user_id = ? OR LOWER(email) = LOWER(?)", userID, user.Email).
+			Updates(map[string]interface{}{"email": "", "ip_address": "", "user_agent": ""}).Error; err != nil {
+			return err
+		}
+
+		// key yang dibuat user tidak boleh tetap bisa dipakai setelah akunnya dihapus
+		if err := tx.Model(&merchant.MerchantAPIKey{}).
+			Where("created_by = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+
+		memberUpdates := map[string]interface{}{
+			"status":                merchant.MemberStatusRemoved,
+			"removed_at":            now,
+			"email":                 anonymizedEmail,
+			"invitation_token_hash": "",
+		}
+
+		if err := tx.Model(&merchant.MerchantMember{}).
+			Where("user_id = ?", userID).
+			Updates(memberUpdates).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(audit).Error
+	})
+}
@@ -0,0 +1,94 @@
+package privacy
+
+import (
+	"testing"
+
+	"go-fiber-api/internal/features/auth"
+	"go-fiber-api/internal/features/follow"
+	"go-fiber-api/internal/features/merchant"
+	"go-fiber-api/internal/util/permission"
+	"go-fiber-api/internal/util/testdb"
+
+	"github.com/google/uuid"
+)
+
+func TestAnonymizeUserRevokesAPIKeysAndScrubsAuditLogs(t *testing.T) {
+	db := testdb.Open(t,
+		&auth.User{},
+		&auth.Session{},
+		&auth.UserIdentity{},
+		&auth.UserTwoFactor{},
+		&auth.RecoveryCode{},
+		&auth.LoginChallenge{},
+		&auth.PasswordResetToken{},
+		&auth.AuditLog{},
+		&merchant.Merchant{},
+		&merchant.MerchantMember{},
+		&merchant.MerchantAPIKey{},
+		&follow.Follow{},
+	)
+
+	email := uuid.NewString() + "@example.com"
+	user := auth.User{Email: email, Password: "hashed"}
+	owner := auth.User{Email: uuid.NewString() + "@example.com", Password: "hashed"}
+	for _, u := range []*auth.User{&user, &owner} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	m := merchant.Merchant{UserID: owner.ID, Name: "Test Merchant"}
+	if err := db.Create(&m).Error; err != nil {
+		t.Fatalf("create merchant: %v", err)
+	}
+
+	member := merchant.MerchantMember{MerchantID: m.ID, UserID: &user.ID, Email: email, Role: permission.MerchantRoleManager, Status: merchant.MemberStatusActive}
+	if err := db.Create(&member).Error; err != nil {
+		t.Fatalf("create member: %v", err)
+	}
+
+	apiKey := merchant.MerchantAPIKey{MerchantID: m.ID, Name: "pos", Prefix: "mk_test", KeyHash: uuid.NewString(), Scopes: []string{permission.MerchantProductsRead}, CreatedBy: user.ID}
+	if err := db.Create(&apiKey).Error; err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+
+	// satu audit terhubung ke user, satu lagi hanya lewat email (login gagal)
+	audits := []auth.AuditLog{
+		{UserID: &user.ID, Event: auth.AuditEventAccountLocked, Email: email, IPAddress: "10.0.0.1", UserAgent: "curl"},
+		{Event: auth.AuditEventAccountLocked, Email: email, IPAddress: "10.0.0.2", UserAgent: "curl"},
+	}
+	if err := db.Create(&audits).Error; err != nil {
+		t.Fatalf("create audit logs: %v", err)
+	}
+
+	repo := NewPrivacyRepository(db)
+	deleted := &auth.AuditLog{UserID: &user.ID, Event: auth.AuditEventAccountDeleted}
+
+	if err := repo.AnonymizeUser(user.ID, "deleted-"+user.ID.String()+"@deleted.invalid", deleted); err != nil {
+		t.Fatalf("AnonymizeUser: %v", err)
+	}
+
+	var revoked merchant.MerchantAPIKey
+	if err := db.First(&revoked, "id = ?", apiKey.ID).Error; err != nil {
+		t.Fatalf("find api key: %v", err)
+	}
+
+	if revoked.RevokedAt == nil {
+		t.Error("api key created by the deleted user is still active")
+	}
+
+	var remaining []auth.AuditLog
+	if err := db.Where("id IN ?", []uuid.UUID{audits[0].ID, audits[1].ID, deleted.ID}).Find(&remaining).Error; err != nil {
+		t.Fatalf("find audit logs: %v", err)
+	}
+
+	if len(remaining) != 3 {
+		t.Fatalf("audit logs = %d, want 3", len(remaining))
+	}
+
+	for _, audit := range remaining {
+		if audit.Email != "" || audit.IPAddress != "" || audit.UserAgent != "" {
+			t.Errorf("audit %s still has email/ip/user agent: %q/%q/%q", audit.Event, audit.Email, audit.IPAddress, audit.UserAgent)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package privacy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-fiber-api/internal/features/auth"
+	util "go-fiber-api/internal/util/password"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	dataExportTTL = 7 * 24 * time.Hour
+	// job PENDING/PROCESSING yang tidak berubah selama ini dianggap mati
+	// (proses restart di tengah export) supaya user bisa meminta lagi
+	dataExportStaleAfter = 30 * time.Minute
+)
+
+var (
+	ErrExportNotFound      = errors.New("data export not found")
+	ErrExportNotReady      = errors.New("data export is not ready")
+	ErrExportExpired       = errors.New("data export has expired")
+	ErrExportInProgress    = errors.New("a data export is already in progress")
+	ErrInvalidPassword     = errors.New("invalid password")
+	ErrMerchantOwnerDelete = errors.New("transfer or delete owned merchants before deleting the account")
+	ErrAccountDeleted      = errors.New("account has already been deleted")
+)
+
+type PrivacyService interface {
+	RequestExport(userID uuid.UUID) (*DataExportDTO, error)
+	GetExports(userID uuid.UUID) ([]DataExportDTO, error)
+	GetExportFile(userID uuid.UUID, exportID uuid.UUID) (string, error)
+	DeleteAccount(userID uuid.UUID, req *DeleteAccountRequest) error
+}
+
+type privacyService struct {
+	repo      PrivacyRepository
+	exportDir string
+}
+
+func NewPrivacyService(repo PrivacyRepository, exportDir string) PrivacyService {
+	return &privacyService{
+		repo:      repo,
+		exportDir: exportDir,
+	}
+}
+
+// RequestExport membuat job export baru, file ZIP dibangun di background
+func (s *privacyService) RequestExport(userID uuid.UUID) (*DataExportDTO, error) {
+	if err := s.repo.FailStaleExports(userID, time.Now().Add(-dataExportStaleAfter)); err != nil {
+		return nil, err
+	}
+
+	running, err := s.repo.FindInProgressExport(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if running != nil {
+		return nil, ErrExportInProgress
+	}
+
+	export := &DataExport{
+		UserID: userID,
+		Status: ExportStatusPending,
+	}
+
+	if err := s.repo.CreateExport(export); err != nil {
+		return nil, err
+	}
+
+	go s.buildExport(export.ID, userID)
+
+	dto := toDataExportDTO(export)
+	return &dto, nil
+}
+
+func (s *privacyService) GetExports(userID uuid.UUID) ([]DataExportDTO, error) {
+	exports, err := s.repo.GetExportsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]DataExportDTO, 0, len(exports))
+	for i := range exports {
+		result = append(result, toDataExportDTO(&exports[i]))
+	}
+
+	return result, nil
+}
+
+// GetExportFile mengembalikan path file ZIP, hanya untuk pemilik export
+func (s *privacyService) GetExportFile(userID uuid.UUID, exportID uuid.UUID) (string, error) {
+	export, err := s.repo.FindExportByID(exportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrExportNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if export.UserID != userID {
+		return "", ErrExportNotFound
+	}
+
+	if export.Status != ExportStatusReady {
+		return "", ErrExportNotReady
+	}
+
+	if export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt) {
+		os.Remove(export.FilePath)
+		return "", ErrExportExpired
+	}
+
+	return export.FilePath, nil
+}
+
+// DeleteAccount meng-anonymize user. Merchant milik user harus dipindahkan
+// atau dihapus dulu supaya toko tidak kehilangan owner
+func (s *privacyService) DeleteAccount(userID uuid.UUID, req *DeleteAccountRequest) error {
+	user, err := s.repo.FindUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.AnonymizedAt.Valid {
+		return ErrAccountDeleted
+	}
+
+	// akun dari OIDC tidak punya password, konfirmasi cukup lewat field confirmation
+	if user.Password != "" {
+		if err := util.CheckPassword(user.Password, req.Password); err != nil {
+			return ErrInvalidPassword
+		}
+	}
+
+	merchants, err := s.repo.FindOwnedMerchants(userID)
+	if err != nil {
+		return err
+	}
+
+	if len(merchants) > 0 {
+		return ErrMerchantOwnerDelete
+	}
+
+	// tanpa IP dan user agent, data pribadi di audit log ikut dihapus
+	audit := &auth.AuditLog{
+		UserID: &user.ID,
+		Event:  auth.AuditEventAccountDeleted,
+	}
+
+	anonymizedEmail := fmt.Sprintf("deleted-%s@deleted.invalid", user.ID)
+
+	if err := s.repo.AnonymizeUser(user.ID, anonymizedEmail, audit); err != nil {
+		return err
+	}
+
+	s.removeExports(user.ID)
+
+	return nil
+}
+
+func (s *privacyService) buildExport(exportID uuid.UUID, userID uuid.UUID) {
+	// berjalan di goroutine sendiri, panic di sini akan mematikan server
+	// dan meninggalkan job PROCESSING selamanya
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("data export %s panicked: %v", exportID, r)
+
+			s.repo.UpdateExport(exportID, map[string]interface{}{
+				"status": ExportStatusFailed,
+				"error":  "internal error while building export",
+			})
+		}
+	}()
+
+	if err := s.repo.UpdateExport(exportID, map[string]interface{}{"status": ExportStatusProcessing}); err != nil {
+		log.Printf("data export %s: %v", exportID, err)
+		return
+	}
+
+	path, size, err := s.writeArchive(exportID, userID)
+	if err != nil {
+		log.Printf("data export %s failed: %v", exportID, err)
+
+		s.repo.UpdateExport(exportID, map[string]interface{}{
+			"status": ExportStatusFailed,
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+
+	err = s.repo.UpdateExport(exportID, map[string]interface{}{
+		"status":       ExportStatusReady,
+		"file_path":    path,
+		"file_size":    size,
+		"completed_at": now,
+		"expires_at":   now.Add(dataExportTTL),
+	})
+
+	if err != nil {
+		log.Printf("data export %s: %v", exportID, err)
+	}
+}
+
+func (s *privacyService) writeArchive(exportID uuid.UUID, userID uuid.UUID) (string, int64, error) {
+	archive, err := s.collect(userID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := os.MkdirAll(s.exportDir, 0o700); err != nil {
+		return "", 0, err
+	}
+
+	path := filepath.Join(s.exportDir, fmt.Sprintf("%s_%s.zip", userID, exportID))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+
+	w, err := zw.Create("data.json")
+	if err != nil {
+		return "", 0, err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(archive); err != nil {
+		return "", 0, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	return path, info.Size(), nil
+}
+
+func (s *privacyService) collect(userID uuid.UUID) (*ExportArchive, error) {
+	user, err := s.repo.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.FindSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trx, err := s.repo.FindTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	follows, err := s.repo.FindFollows(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchants, err := s.repo.FindOwnedMerchants(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.FindMemberships(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &ExportArchive{
+		ExportedAt: time.Now().UTC(),
+		Profile: ExportProfile{
+			ID:               user.ID,
+			Email:            user.Email,
+			Role:             string(user.Role),
+			EmailVerifiedAt:  nullTimePtr(user.EmailVerifiedAt.Time, user.EmailVerifiedAt.Valid),
+			DisplayName:      user.DisplayName,
+			PhoneNumber:      user.PhoneNumber,
+			AvatarURL:        user.AvatarURL,
+			Locale:           user.Locale,
+			MarketingConsent: user.MarketingConsent,
+			CreatedAt:        nullTimePtr(user.CreatedAt.Time, user.CreatedAt.Valid),
+		},
+		Sessions:     make([]ExportSession, 0, len(sessions)),
+		Transactions: make([]ExportTransaction, 0, len(trx)),
+		Follows:      make([]ExportFollow, 0, len(follows)),
+		Merchants:    make([]ExportMerchant, 0, len(merchants)),
+		Memberships:  make([]ExportMembership, 0, len(members)),
+	}
+
+	for _, session := range sessions {
+		archive.Sessions = append(archive.Sessions, ExportSession{
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			LastUsedAt: session.LastUsedAt,
+			RevokedAt:  session.RevokedAt,
+			CreatedAt:  session.CreatedAt,
+		})
+	}
+
+	for _, t := range trx {
+		items := make([]ExportTransactionItem, 0, len(t.Items))
+		for _, item := range t.Items {
+			items = append(items, ExportTransactionItem{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				Price:     item.Price,
+				Subtotal:  item.Subtotal,
+			})
+		}
+
+		archive.Transactions = append(archive.Transactions, ExportTransaction{
+			ID:          t.ID,
+			OrderID:     t.OrderID,
+			MerchantID:  t.MerchantID,
+			Status:      string(t.Status),
+			TotalAmount: t.TotalAmount,
+			PaymentType: t.PaymentType,
+			Items:       items,
+			CreatedAt:   t.CreatedAt,
+		})
+	}
+
+	for _, f := range follows {
+		archive.Follows = append(archive.Follows, ExportFollow{
+			MerchantID: f.MerchantID,
+			CreatedAt:  f.CreatedAt,
+		})
+	}
+
+	for _, m := range merchants {
+		archive.Merchants = append(archive.Merchants, ExportMerchant{
+			ID:          m.ID,
+			Name:        m.Name,
+			Description: m.Description,
+			Location:    m.Location,
+		})
+	}
+
+	for _, m := range members {
+		archive.Memberships = append(archive.Memberships, ExportMembership{
+			MerchantID: m.MerchantID,
+			Role:       string(m.Role),
+			Status:     string(m.Status),
+			AcceptedAt: m.AcceptedAt,
+		})
+	}
+
+	return archive, nil
+}
+
+// removeExports menghapus file export lama, error diabaikan karena
+// datanya sudah dianonymize
+func (s *privacyService) removeExports(userID uuid.UUID) {
+	exports, err := s.repo.GetExportsByUserID(userID)
+	if err != nil {
+		return
+	}
+
+	for _, export := range exports {
+		if export.FilePath != "" {
+			os.Remove(export.FilePath)
+		}
+	}
+}
+
+func toDataExportDTO(export *DataExport) DataExportDTO {
+	return DataExportDTO{
+		ID:          export.ID,
+		Status:      string(export.Status),
+		FileSize:    export.FileSize,
+		Error:       export.Error,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+		CreatedAt:   export.CreatedAt,
+	}
+}
+
+func nullTimePtr(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+
+	return &t
+}
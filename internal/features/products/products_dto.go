@@ -24,8 +24,11 @@ type ProductDTO struct {
 	Price           decimal.Decimal `json:"price"`
 	Quantity        int             `json:"quantity"`
 	ProductPhotoUrl string          `json:"product_photo_url"`
-	CreatedAt       sql.NullTime    `json:"created_at"`
-	UpdatedAt       sql.NullTime    `json:"updated_at"`
+
+	ReservedQuantity  int          `json:"reserved_quantity"`
+	AvailableQuantity int          `json:"available_quantity"`
+	CreatedAt         sql.NullTime `json:"created_at"`
+	UpdatedAt         sql.NullTime `json:"updated_at"`
 }
 
 type BulkDeleteProductRequest struct {
@@ -41,6 +44,9 @@ type ProductDashboard struct {
 	Quantity        int             `json:"quantity"`
 	ProductPhotoUrl string          `json:"product_photo_url"`
 
+	ReservedQuantity  int `json:"reserved_quantity"`
+	AvailableQuantity int `json:"available_quantity"`
+
 	CreatedAt sql.NullTime `json:"created_at"`
 	UpdatedAt sql.NullTime `json:"updated_at"`
 }
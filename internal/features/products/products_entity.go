@@ -18,7 +18,15 @@ type Product struct {
 	Quantity        int             `gorm:"not null"`
 	ProductPhotoUrl string          `gorm:"type:text;not null"`
 
+	// ReservedQuantity adalah stok yang ditahan transaksi yang belum lunas
+	ReservedQuantity int `gorm:"not null;default:0"`
+
 	CreatedAt sql.NullTime
 	UpdatedAt sql.NullTime
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
+
+// AvailableQuantity adalah stok yang masih bisa dibeli
+func (p Product) AvailableQuantity() int {
+	return p.Quantity - p.ReservedQuantity
+}
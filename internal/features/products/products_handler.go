@@ -24,12 +24,14 @@ type ProductHandler interface {
 type productHandler struct {
 	productService  ProductService
 	merchantService MerchantServiceContract
+	storage         upload.Storage
 }
 
-func NewProductHandler(productService ProductService, merchantService MerchantServiceContract) ProductHandler {
+func NewProductHandler(productService ProductService, merchantService MerchantServiceContract, storage upload.Storage) ProductHandler {
 	return &productHandler{
 		productService:  productService,
 		merchantService: merchantService,
+		storage:         storage,
 	}
 }
 
@@ -43,7 +45,6 @@ func (ph *productHandler) CreateProduct(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusBadRequest, "invalid form data")
 	}
 
-	user_id := c.Locals("user_id").(*token.CustomClaims).UserID
 	merchantIDParam := c.Params("merchant_id")
 
 	var request CreateProductRequest
@@ -64,16 +65,7 @@ func (ph *productHandler) CreateProduct(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusBadRequest, "invalid merchant id")
 	}
 
-	// Ambil merchant
-	merchant, err := ph.merchantService.GetMerchantById(merchantID)
-	if err != nil {
-		return response.Fail(c, fiber.StatusNotFound, "merchant not found")
-	}
-
-	// Authorization: pastikan merchant milik user
-	if merchant.UserID != user_id {
-		return response.Fail(c, fiber.StatusForbidden, "you are not allowed to access this merchant")
-	}
+	// Authorization merchant sudah dicek di middleware.RequirePermission
 
 	// Handle upload product photo
 	productPhotoFiles := form.File["product_photo_url"]
@@ -81,7 +73,7 @@ func (ph *productHandler) CreateProduct(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusBadRequest, "product photo is required")
 	}
 
-	uploadResult, err := upload.UploadToSupabaseStorage(ctx, productPhotoFiles[0], "products")
+	uploadResult, err := ph.storage.UploadToSupabaseStorage(ctx, productPhotoFiles[0], "products")
 	if err != nil {
 		return response.FailWithData(c, fiber.StatusInternalServerError, "failed to upload product photo", err.Error())
 	}
@@ -129,19 +121,21 @@ func (ph *productHandler) BulkDeleteMerchantProducts(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusBadRequest, "product_ids cannot be empty")
 	}
 
-	merchant, err := ph.merchantService.GetMyMerchants(userID)
-
+	// route lama tanpa :merchant_id memakai merchant milik user
+	merchantID, err := uuid.Parse(c.Params("merchant_id"))
 	if err != nil {
-		return response.Fail(c, fiber.StatusInternalServerError, "failed to get merchant")
-	}
+		merchant, err := ph.merchantService.GetMyMerchants(userID)
+
+		if err != nil {
+			return response.Fail(c, fiber.StatusInternalServerError, "failed to get merchant")
+		}
 
-	if merchant.UserID != userID {
-		return response.Fail(c, fiber.StatusForbidden, "you are not allowed to access this merchant")
+		merchantID = merchant.ID
 	}
 
 	if err := ph.productService.DeleteMerchantProduct(
 		req.ProductIDs,
-		merchant.ID,
+		merchantID,
 	); err != nil {
 		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
 	}
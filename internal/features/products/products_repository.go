@@ -69,7 +69,7 @@ func (pr *productRepository) GetMerchantProducts(merchantID uuid.UUID) ([]Produc
 }
 
 func (pr *productRepository) DeleteMerchantProduct(productID []uuid.UUID, merchantID uuid.UUID) error {
-	result := pr.db.Where("id IN ?", productID).Delete(&Product{})
+	result := pr.db.Where("id IN ? AND merchant_id = ?", productID, merchantID).Delete(&Product{})
 	log.Printf("delete products | rows=%d | err=%v", result.RowsAffected, result.Error)
 
 	if result.Error != nil {
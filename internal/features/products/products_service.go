@@ -48,15 +48,17 @@ func (ps *productService) CreateProduct(req *CreateProductRequest) (*ProductDTO,
 	}
 
 	return &ProductDTO{
-		ID:              createdProduct.ID,
-		MerchantID:      createdProduct.MerchantID,
-		Name:            createdProduct.Name,
-		Description:     createdProduct.Description,
-		Price:           createdProduct.Price,
-		Quantity:        createdProduct.Quantity,
-		ProductPhotoUrl: createdProduct.ProductPhotoUrl,
-		CreatedAt:       createdProduct.CreatedAt,
-		UpdatedAt:       createdProduct.UpdatedAt,
+		ID:                createdProduct.ID,
+		MerchantID:        createdProduct.MerchantID,
+		Name:              createdProduct.Name,
+		Description:       createdProduct.Description,
+		Price:             createdProduct.Price,
+		Quantity:          createdProduct.Quantity,
+		ProductPhotoUrl:   createdProduct.ProductPhotoUrl,
+		ReservedQuantity:  createdProduct.ReservedQuantity,
+		AvailableQuantity: createdProduct.AvailableQuantity(),
+		CreatedAt:         createdProduct.CreatedAt,
+		UpdatedAt:         createdProduct.UpdatedAt,
 	}, nil
 }
 
@@ -71,15 +73,17 @@ func (ps *productService) GetMerchantProducts(merchantID uuid.UUID) ([]ProductDT
 
 	for _, e := range products {
 		responses = append(responses, ProductDTO{
-			ID:              e.ID,
-			MerchantID:      e.MerchantID,
-			Name:            e.Name,
-			Description:     e.Description,
-			Price:           e.Price,
-			Quantity:        e.Quantity,
-			ProductPhotoUrl: e.ProductPhotoUrl,
-			CreatedAt:       e.CreatedAt,
-			UpdatedAt:       e.UpdatedAt,
+			ID:                e.ID,
+			MerchantID:        e.MerchantID,
+			Name:              e.Name,
+			Description:       e.Description,
+			Price:             e.Price,
+			Quantity:          e.Quantity,
+			ProductPhotoUrl:   e.ProductPhotoUrl,
+			ReservedQuantity:  e.ReservedQuantity,
+			AvailableQuantity: e.AvailableQuantity(),
+			CreatedAt:         e.CreatedAt,
+			UpdatedAt:         e.UpdatedAt,
 		})
 	}
 
@@ -101,15 +105,17 @@ func (ps *productService) GetMerchantProductsDashboard(merchantID uuid.UUID) ([]
 	responses := make([]ProductDashboard, 0, len(products))
 	for _, e := range products {
 		responses = append(responses, ProductDashboard{
-			ID:              e.ID,
-			MerchantID:      e.MerchantID,
-			Name:            e.Name,
-			Description:     e.Description,
-			Price:           e.Price,
-			Quantity:        e.Quantity,
-			ProductPhotoUrl: e.ProductPhotoUrl,
-			CreatedAt:       e.CreatedAt,
-			UpdatedAt:       e.UpdatedAt,
+			ID:                e.ID,
+			MerchantID:        e.MerchantID,
+			Name:              e.Name,
+			Description:       e.Description,
+			Price:             e.Price,
+			Quantity:          e.Quantity,
+			ProductPhotoUrl:   e.ProductPhotoUrl,
+			ReservedQuantity:  e.ReservedQuantity,
+			AvailableQuantity: e.AvailableQuantity(),
+			CreatedAt:         e.CreatedAt,
+			UpdatedAt:         e.UpdatedAt,
 		})
 	}
 
@@ -0,0 +1,88 @@
+package transactions
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// expiryLockKey dipakai sebagai advisory lock Postgres supaya hanya
+	// satu instance API yang menjalankan sweep pada satu waktu
+	expiryLockKey   = "transactions:expire-pending"
+	expiryBatchSize = 100
+)
+
+type ExpiryScheduler interface {
+	Start(ctx context.Context)
+	RunOnce() error
+}
+
+type expiryScheduler struct {
+	db       *gorm.DB
+	service  TransactionService
+	ttl      time.Duration
+	interval time.Duration
+}
+
+func NewExpiryScheduler(db *gorm.DB, service TransactionService, ttl time.Duration, interval time.Duration) ExpiryScheduler {
+	return &expiryScheduler{
+		db:       db,
+		service:  service,
+		ttl:      ttl,
+		interval: interval,
+	}
+}
+
+// Start menjalankan sweep tiap interval di goroutine terpisah sampai ctx selesai
+func (s *expiryScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.RunOnce(); err != nil {
+					log.Println("transaction expiry failed:", err)
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce memegang advisory lock di satu koneksi selama sweep. Instance
+// lain yang tidak mendapat lock melewati putaran ini
+func (s *expiryScheduler) RunOnce() error {
+	return s.db.Connection(func(conn *gorm.DB) error {
+		var locked bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(hashtext(?))", expiryLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+
+		if !locked {
+			return nil
+		}
+
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(hashtext(?))", expiryLockKey).Error; err != nil {
+				log.Println("failed to release transaction expiry lock:", err)
+			}
+		}()
+
+		expired, err := s.service.ExpirePendingTransactions(s.ttl, expiryBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if expired > 0 {
+			log.Printf("expired %d pending transactions", expired)
+		}
+
+		return nil
+	})
+}
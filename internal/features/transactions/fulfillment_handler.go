@@ -0,0 +1,73 @@
+package transactions
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FulfillmentHandler interface {
+	AdvanceFulfillment(c *fiber.Ctx) error
+}
+
+type fulfillmentHandler struct {
+	service FulfillmentService
+}
+
+func NewFulfillmentHandler(service FulfillmentService) FulfillmentHandler {
+	return &fulfillmentHandler{service: service}
+}
+
+func (h *fulfillmentHandler) AdvanceFulfillment(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, http.StatusUnauthorized, "unauthorized")
+	}
+
+	transactionID, err := uuid.Parse(c.Params("transaction_id"))
+	if err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	var req AdvanceFulfillmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, http.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, http.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	result, err := h.service.Advance(transactionID, claims.UserID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.Fail(c, http.StatusNotFound, "transaction not found")
+		case errors.Is(err, ErrRejectionReasonRequired):
+			return response.Fail(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrFulfillmentNotAllowed),
+			errors.Is(err, ErrInvalidFulfillmentTransition),
+			errors.Is(err, ErrTransactionNotRefundable),
+			errors.Is(err, ErrNothingToRefund):
+			return response.Fail(c, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrRefundFailed):
+			log.Println("fulfillment refund error:", err)
+			return response.Fail(c, http.StatusBadGateway, "failed to refund rejected order")
+		default:
+			log.Println("fulfillment error:", err)
+			return response.Fail(c, http.StatusInternalServerError, "failed to update fulfillment")
+		}
+	}
+
+	return response.Success(c, "fulfillment updated", result)
+}
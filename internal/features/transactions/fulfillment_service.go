@@ -0,0 +1,136 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFulfillmentNotAllowed        = errors.New("only paid transactions can be fulfilled")
+	ErrInvalidFulfillmentTransition = errors.New("fulfillment cannot move to the requested status")
+	ErrRejectionReasonRequired      = errors.New("note is required when rejecting an order")
+)
+
+// maxRefundReasonLength mengikuti lebar kolom refunds.reason
+const maxRefundReasonLength = 255
+
+// fulfillmentTransitions adalah langkah yang boleh diambil merchant dari
+// tiap status. Pesanan hanya bisa ditolak sebelum siap diambil atau dikirim
+var fulfillmentTransitions = map[FulfillmentStatus][]FulfillmentStatus{
+	FulfillmentAwaitingAcceptance: {FulfillmentAccepted, FulfillmentRejected},
+	FulfillmentAccepted:           {FulfillmentPreparing, FulfillmentRejected},
+	FulfillmentPreparing:          {FulfillmentReadyForPickup, FulfillmentShipped, FulfillmentRejected},
+	FulfillmentReadyForPickup:     {FulfillmentCompleted},
+	FulfillmentShipped:            {FulfillmentDelivered},
+	FulfillmentDelivered:          {FulfillmentCompleted},
+}
+
+type FulfillmentService interface {
+	Advance(transactionID uuid.UUID, actorID uuid.UUID, req *AdvanceFulfillmentRequest) (*FulfillmentResponse, error)
+}
+
+type fulfillmentService struct {
+	transactionRepository TransactionRepository
+	refundService         RefundService
+}
+
+func NewFulfillmentService(transactionRepo TransactionRepository, refundService RefundService) FulfillmentService {
+	return &fulfillmentService{
+		transactionRepository: transactionRepo,
+		refundService:         refundService,
+	}
+}
+
+// Advance memindahkan fulfillment satu langkah. Penolakan langsung
+// me-refund seluruh sisa pembayaran dan mengembalikan stok, kalau refund
+// gagal status fulfillment dikembalikan supaya bisa dicoba lagi
+func (s *fulfillmentService) Advance(transactionID uuid.UUID, actorID uuid.UUID, req *AdvanceFulfillmentRequest) (*FulfillmentResponse, error) {
+	transaction, err := s.transactionRepository.FindByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Status != TransactionStatusPaid && transaction.Status != TransactionStatusPartiallyRefunded {
+		return nil, ErrFulfillmentNotAllowed
+	}
+
+	current := transaction.CurrentFulfillment()
+	if !canTransitionFulfillment(current, req.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidFulfillmentTransition, current, req.Status)
+	}
+
+	if req.Status == FulfillmentRejected && req.Note == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	ok, err := s.transactionRepository.TransitionFulfillment(transaction.ID, []FulfillmentStatus{current}, req.Status, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		// sudah dipindahkan request lain atau transaksinya baru saja di-refund
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidFulfillmentTransition, current, req.Status)
+	}
+
+	resp := &FulfillmentResponse{
+		TransactionID:     transaction.ID,
+		Status:            string(transaction.Status),
+		FulfillmentStatus: string(req.Status),
+		FulfillmentNote:   req.Note,
+	}
+
+	if req.Status != FulfillmentRejected {
+		return resp, nil
+	}
+
+	refund, err := s.refundService.CreateRefund(transaction.ID, actorID, &CreateRefundRequest{
+		Reason:  rejectionRefundReason(req.Note),
+		Restock: true,
+	})
+	if err != nil {
+		if _, revertErr := s.transactionRepository.TransitionFulfillment(
+			transaction.ID,
+			[]FulfillmentStatus{FulfillmentRejected},
+			current,
+			transaction.FulfillmentNote,
+		); revertErr != nil {
+			log.Println("revert fulfillment error:", revertErr)
+		}
+		return nil, err
+	}
+
+	resp.Refund = refund
+
+	updated, err := s.transactionRepository.FindByID(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp.Status = string(updated.Status)
+
+	return resp, nil
+}
+
+func canTransitionFulfillment(from FulfillmentStatus, to FulfillmentStatus) bool {
+	for _, next := range fulfillmentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectionRefundReason dipotong supaya catatan penolakan yang panjang
+// tidak menggagalkan insert refund
+func rejectionRefundReason(note string) string {
+	reason := "rejected by merchant: " + note
+	if utf8.RuneCountInString(reason) <= maxRefundReasonLength {
+		return reason
+	}
+
+	return string([]rune(reason)[:maxRefundReasonLength])
+}
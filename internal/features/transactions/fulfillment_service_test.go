@@ -0,0 +1,142 @@
+package transactions
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type memoryTransactionRepository struct {
+	TransactionRepository
+	transaction *Transaction
+}
+
+func (r *memoryTransactionRepository) FindByID(id uuid.UUID) (*Transaction, error) {
+	copied := *r.transaction
+	return &copied, nil
+}
+
+func (r *memoryTransactionRepository) TransitionFulfillment(id uuid.UUID, from []FulfillmentStatus, to FulfillmentStatus, note string) (bool, error) {
+	for _, status := range from {
+		if r.transaction.CurrentFulfillment() == status {
+			r.transaction.FulfillmentStatus = to
+			r.transaction.FulfillmentNote = note
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type recordingRefundService struct {
+	RefundService
+	requests []*CreateRefundRequest
+	err      error
+}
+
+func (s *recordingRefundService) CreateRefund(transactionID uuid.UUID, requesterID uuid.UUID, req *CreateRefundRequest) (*RefundDTO, error) {
+	s.requests = append(s.requests, req)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &RefundDTO{Reason: req.Reason}, nil
+}
+
+func newFulfillmentTest(status TransactionStatus, fulfillment FulfillmentStatus) (*memoryTransactionRepository, *recordingRefundService, FulfillmentService) {
+	repo := &memoryTransactionRepository{transaction: &Transaction{
+		ID:                uuid.New(),
+		Status:            status,
+		FulfillmentStatus: fulfillment,
+	}}
+	refunds := &recordingRefundService{}
+
+	return repo, refunds, NewFulfillmentService(repo, refunds)
+}
+
+func TestAdvanceFulfillmentTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  TransactionStatus
+		from    FulfillmentStatus
+		to      FulfillmentStatus
+		wantErr error
+	}{
+		{name: "accept new order", status: TransactionStatusPaid, to: FulfillmentAccepted},
+		{name: "ship prepared order", status: TransactionStatusPaid, from: FulfillmentPreparing, to: FulfillmentShipped},
+		{name: "complete delivered order", status: TransactionStatusPartiallyRefunded, from: FulfillmentDelivered, to: FulfillmentCompleted},
+		{name: "skip preparing", status: TransactionStatusPaid, from: FulfillmentAccepted, to: FulfillmentShipped, wantErr: ErrInvalidFulfillmentTransition},
+		{name: "reject shipped order", status: TransactionStatusPaid, from: FulfillmentShipped, to: FulfillmentRejected, wantErr: ErrInvalidFulfillmentTransition},
+		{name: "reopen completed order", status: TransactionStatusPaid, from: FulfillmentCompleted, to: FulfillmentAccepted, wantErr: ErrInvalidFulfillmentTransition},
+		{name: "unpaid order", status: TransactionStatusPending, to: FulfillmentAccepted, wantErr: ErrFulfillmentNotAllowed},
+		{name: "refunded order", status: TransactionStatusRefunded, to: FulfillmentAccepted, wantErr: ErrFulfillmentNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _, service := newFulfillmentTest(tt.status, tt.from)
+
+			_, err := service.Advance(repo.transaction.ID, uuid.New(), &AdvanceFulfillmentRequest{Status: tt.to, Note: "JNE123"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Advance error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Advance: %v", err)
+			}
+
+			if repo.transaction.FulfillmentStatus != tt.to {
+				t.Fatalf("fulfillment status = %s, want %s", repo.transaction.FulfillmentStatus, tt.to)
+			}
+		})
+	}
+}
+
+func TestRejectFulfillmentRefundsOrder(t *testing.T) {
+	repo, refunds, service := newFulfillmentTest(TransactionStatusPaid, FulfillmentAccepted)
+
+	if _, err := service.Advance(repo.transaction.ID, uuid.New(), &AdvanceFulfillmentRequest{Status: FulfillmentRejected}); !errors.Is(err, ErrRejectionReasonRequired) {
+		t.Fatalf("reject without note error = %v, want %v", err, ErrRejectionReasonRequired)
+	}
+
+	// catatan maksimal 255 karakter tetap harus muat di kolom reason refund
+	note := strings.Repeat("é", 255)
+
+	if _, err := service.Advance(repo.transaction.ID, uuid.New(), &AdvanceFulfillmentRequest{Status: FulfillmentRejected, Note: note}); err != nil {
+		t.Fatalf("Advance: %v", err)
+	}
+
+	if len(refunds.requests) != 1 {
+		t.Fatalf("refunds = %d, want 1", len(refunds.requests))
+	}
+
+	req := refunds.requests[0]
+	if !req.Restock || len(req.Items) != 0 {
+		t.Fatalf("refund request = %+v, want full refund with restock", req)
+	}
+
+	if n := utf8.RuneCountInString(req.Reason); n != maxRefundReasonLength {
+		t.Fatalf("refund reason length = %d, want %d", n, maxRefundReasonLength)
+	}
+
+	if !strings.HasPrefix(req.Reason, "rejected by merchant: ") {
+		t.Fatalf("refund reason = %q, want rejection prefix", req.Reason)
+	}
+}
+
+func TestRejectFulfillmentRevertsWhenRefundFails(t *testing.T) {
+	repo, refunds, service := newFulfillmentTest(TransactionStatusPaid, FulfillmentPreparing)
+	refunds.err = ErrRefundFailed
+
+	if _, err := service.Advance(repo.transaction.ID, uuid.New(), &AdvanceFulfillmentRequest{Status: FulfillmentRejected, Note: "out of stock"}); !errors.Is(err, ErrRefundFailed) {
+		t.Fatalf("Advance error = %v, want %v", err, ErrRefundFailed)
+	}
+
+	if repo.transaction.FulfillmentStatus != FulfillmentPreparing {
+		t.Fatalf("fulfillment status = %s, want %s after failed refund", repo.transaction.FulfillmentStatus, FulfillmentPreparing)
+	}
+}
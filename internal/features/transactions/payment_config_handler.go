@@ -0,0 +1,54 @@
+package transactions
+
+import (
+	"time"
+
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentConfigHandler interface {
+	GetPaymentConfig(c *fiber.Ctx) error
+}
+
+type paymentConfigHandler struct {
+	config PaymentConfigResponse
+}
+
+// NewPaymentConfigHandler: pengaturan Snap hanya dikirim kalau provider-nya
+// midtrans, fake gateway cukup memakai redirect_url dari transaksi
+func NewPaymentConfigHandler(provider string, cfg config.MidtransConfig) PaymentConfigHandler {
+	if provider != "midtrans" {
+		return &paymentConfigHandler{
+			config: PaymentConfigResponse{
+				Provider:        provider,
+				EnabledPayments: []string{},
+			},
+		}
+	}
+
+	enabledPayments := cfg.EnabledPayments
+	if enabledPayments == nil {
+		enabledPayments = []string{}
+	}
+
+	return &paymentConfigHandler{
+		config: PaymentConfigResponse{
+			Provider:        "midtrans",
+			Environment:     cfg.Environment,
+			ClientKey:       cfg.ClientKey,
+			MerchantID:      cfg.MerchantID,
+			SnapJSURL:       cfg.SnapJSURL(),
+			EnabledPayments: enabledPayments,
+			ExpiryMinutes:   int64(cfg.Expiry / time.Minute),
+		},
+	}
+}
+
+func (h *paymentConfigHandler) GetPaymentConfig(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+
+	return response.Success(c, "payment config retrieved", h.config)
+}
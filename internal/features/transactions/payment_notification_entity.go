@@ -0,0 +1,24 @@
+package transactions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RejectedPaymentNotification menyimpan notifikasi Midtrans yang ditolak
+// (signature salah, nominal beda, order tidak dikenal) untuk diinvestigasi
+type RejectedPaymentNotification struct {
+	ID      uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	OrderID string    `gorm:"type:varchar(100);index"`
+	Reason  string    `gorm:"type:varchar(255);not null"`
+
+	TransactionStatus string `gorm:"type:varchar(50)"`
+	StatusCode        string `gorm:"type:varchar(10)"`
+	GrossAmount       string `gorm:"type:varchar(50)"`
+
+	Payload   string `gorm:"type:text"`
+	IPAddress string `gorm:"type:varchar(64)"`
+
+	CreatedAt time.Time `gorm:"index"`
+}
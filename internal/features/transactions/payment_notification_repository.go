@@ -0,0 +1,21 @@
+package transactions
+
+import (
+	"gorm.io/gorm"
+)
+
+type PaymentNotificationRepository interface {
+	CreateRejected(notification *RejectedPaymentNotification) error
+}
+
+type paymentNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentNotificationRepository(db *gorm.DB) PaymentNotificationRepository {
+	return &paymentNotificationRepository{db: db}
+}
+
+func (r *paymentNotificationRepository) CreateRejected(notification *RejectedPaymentNotification) error {
+	return r.db.Create(notification).Error
+}
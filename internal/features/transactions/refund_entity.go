@@ -0,0 +1,47 @@
+package transactions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "PENDING"
+	RefundStatusSucceeded RefundStatus = "SUCCEEDED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+)
+
+// Refund dicatat PENDING sebelum gateway dipanggil, ID-nya dipakai sebagai
+// refund_key supaya retry ke gateway tidak menghasilkan refund ganda
+type Refund struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	TransactionID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	Amount  decimal.Decimal `gorm:"type:decimal(18,2);not null"`
+	Reason  string          `gorm:"type:varchar(255);not null"`
+	Status  RefundStatus    `gorm:"type:varchar(20);not null;default:'PENDING'"`
+	Restock bool            `gorm:"not null;default:false"`
+
+	FailureReason string    `gorm:"type:varchar(255)"`
+	RequestedBy   uuid.UUID `gorm:"type:uuid;not null"`
+	CompletedAt   *time.Time
+
+	Items []RefundItem `gorm:"foreignKey:RefundID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type RefundItem struct {
+	ID                uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	RefundID          uuid.UUID `gorm:"type:uuid;not null;index"`
+	TransactionItemID uuid.UUID `gorm:"type:uuid;not null;index"`
+	ProductID         uuid.UUID `gorm:"type:uuid;not null"`
+
+	Quantity int             `gorm:"type:int;not null"`
+	Amount   decimal.Decimal `gorm:"type:decimal(18,2);not null"`
+}
@@ -0,0 +1,87 @@
+package transactions
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/token"
+	"go-fiber-api/internal/util/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefundHandler interface {
+	CreateRefund(c *fiber.Ctx) error
+	GetRefunds(c *fiber.Ctx) error
+}
+
+type refundHandler struct {
+	service RefundService
+}
+
+func NewRefundHandler(service RefundService) RefundHandler {
+	return &refundHandler{service: service}
+}
+
+func (h *refundHandler) CreateRefund(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, http.StatusUnauthorized, "unauthorized")
+	}
+
+	transactionID, err := uuid.Parse(c.Params("transaction_id"))
+	if err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	var req CreateRefundRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	if errorMessages, err := validation.ValidateStruct(req); err != nil {
+		return response.Fail(c, http.StatusBadRequest, "validation internal error")
+	} else if len(errorMessages) > 0 {
+		return response.FailWithData(c, http.StatusBadRequest, "validation failed", errorMessages)
+	}
+
+	result, err := h.service.CreateRefund(transactionID, claims.UserID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.Fail(c, http.StatusNotFound, "transaction not found")
+		case errors.Is(err, ErrInvalidRefundItem):
+			return response.Fail(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrTransactionNotRefundable),
+			errors.Is(err, ErrRefundQuantityExceeded),
+			errors.Is(err, ErrNothingToRefund):
+			return response.Fail(c, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrRefundFailed):
+			log.Println("refund error:", err)
+			return response.Fail(c, http.StatusBadGateway, "failed to refund payment")
+		default:
+			log.Println("refund error:", err)
+			return response.Fail(c, http.StatusInternalServerError, "failed to refund transaction")
+		}
+	}
+
+	return response.SuccessWithStatus(c, http.StatusCreated, "transaction refunded", result)
+}
+
+func (h *refundHandler) GetRefunds(c *fiber.Ctx) error {
+	transactionID, err := uuid.Parse(c.Params("transaction_id"))
+	if err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	result, err := h.service.GetRefunds(transactionID)
+	if err != nil {
+		return response.Fail(c, http.StatusInternalServerError, "failed to get refunds")
+	}
+
+	return response.Success(c, "refunds retrieved", result)
+}
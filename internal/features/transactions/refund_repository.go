@@ -0,0 +1,95 @@
+package transactions
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type RefundRepository interface {
+	WithTx(tx *gorm.DB) RefundRepository
+	Create(refund *Refund) error
+	FindByTransactionID(transactionID uuid.UUID) ([]Refund, error)
+	SumActiveAmount(transactionID uuid.UUID) (decimal.Decimal, error)
+	MarkSucceeded(id uuid.UUID) error
+	MarkFailed(id uuid.UUID, reason string) error
+}
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) WithTx(tx *gorm.DB) RefundRepository {
+	return &refundRepository{db: tx}
+}
+
+func (r *refundRepository) Create(refund *Refund) error {
+	return r.db.Create(refund).Error
+}
+
+func (r *refundRepository) FindByTransactionID(transactionID uuid.UUID) ([]Refund, error) {
+	var refunds []Refund
+
+	err := r.db.
+		Preload("Items").
+		Where("transaction_id = ?", transactionID).
+		Order("created_at ASC").
+		Find(&refunds).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// SumActiveAmount menjumlahkan refund yang belum gagal, termasuk yang
+// masih diproses gateway
+func (r *refundRepository) SumActiveAmount(transactionID uuid.UUID) (decimal.Decimal, error) {
+	var total decimal.NullDecimal
+
+	err := r.db.
+		Model(&Refund{}).
+		Select("SUM(amount)").
+		Where("transaction_id = ? AND status <> ?", transactionID, RefundStatusFailed).
+		Scan(&total).
+		Error
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	if !total.Valid {
+		return decimal.Zero, nil
+	}
+
+	return total.Decimal, nil
+}
+
+func (r *refundRepository) MarkSucceeded(id uuid.UUID) error {
+	return r.db.
+		Model(&Refund{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       RefundStatusSucceeded,
+			"completed_at": time.Now().UTC(),
+		}).
+		Error
+}
+
+func (r *refundRepository) MarkFailed(id uuid.UUID, reason string) error {
+	return r.db.
+		Model(&Refund{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":         RefundStatusFailed,
+			"failure_reason": reason,
+			"completed_at":   time.Now().UTC(),
+		}).
+		Error
+}
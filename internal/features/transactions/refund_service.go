@@ -0,0 +1,292 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"go-fiber-api/internal/features/inventory"
+	"go-fiber-api/internal/util/payment"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTransactionNotRefundable = errors.New("only paid transactions can be refunded")
+	ErrInvalidRefundItem        = errors.New("refund item does not belong to transaction")
+	ErrRefundQuantityExceeded   = errors.New("refund quantity exceeds remaining quantity")
+	ErrNothingToRefund          = errors.New("nothing left to refund")
+	ErrRefundFailed             = errors.New("payment gateway refund failed")
+)
+
+// refundableStatuses adalah status transaksi yang masih bisa di-refund
+var refundableStatuses = []TransactionStatus{TransactionStatusPaid, TransactionStatusPartiallyRefunded}
+
+type RefundService interface {
+	CreateRefund(transactionID uuid.UUID, requesterID uuid.UUID, req *CreateRefundRequest) (*RefundDTO, error)
+	GetRefunds(transactionID uuid.UUID) ([]RefundDTO, error)
+}
+
+type refundService struct {
+	db                    *gorm.DB
+	transactionRepository TransactionRepository
+	refundRepository      RefundRepository
+	stockMovementRepo     inventory.StockMovementRepository
+	gateway               payment.PaymentGateway
+}
+
+func NewRefundService(
+	db *gorm.DB,
+	transactionRepo TransactionRepository,
+	refundRepo RefundRepository,
+	stockMovementRepo inventory.StockMovementRepository,
+	gateway payment.PaymentGateway,
+) RefundService {
+	return &refundService{
+		db:                    db,
+		transactionRepository: transactionRepo,
+		refundRepository:      refundRepo,
+		stockMovementRepo:     stockMovementRepo,
+		gateway:               gateway,
+	}
+}
+
+// CreateRefund berjalan dalam tiga langkah: jumlah item yang di-refund
+// ditahan bersama record refund PENDING, gateway dipanggil, lalu hasilnya
+// dicatat. Kalau gateway gagal, jumlah item dikembalikan
+func (s *refundService) CreateRefund(transactionID uuid.UUID, requesterID uuid.UUID, req *CreateRefundRequest) (*RefundDTO, error) {
+	transaction, err := s.transactionRepository.GetTransactionsDetailByID(transactionID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Status != TransactionStatusPaid && transaction.Status != TransactionStatusPartiallyRefunded {
+		return nil, ErrTransactionNotRefundable
+	}
+
+	quantities, err := refundQuantities(transaction.Items, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &Refund{
+		TransactionID: transaction.ID,
+		Reason:        req.Reason,
+		Status:        RefundStatusPending,
+		Restock:       req.Restock,
+		RequestedBy:   requesterID,
+	}
+
+	var fullyRefunded bool
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		itemRepo := NewTransactionItemRepository(tx)
+		refundRepo := s.refundRepository.WithTx(tx)
+
+		amount := decimal.Zero
+		for _, item := range transaction.Items {
+			quantity := quantities[item.ID]
+			if quantity == 0 {
+				continue
+			}
+
+			// kondisi di query mencegah dua refund bersamaan melebihi jumlah beli
+			ok, err := itemRepo.AddRefundedQuantity(item.ID, quantity)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrRefundQuantityExceeded
+			}
+
+			itemAmount := item.Price.Mul(decimal.NewFromInt(int64(quantity)))
+			amount = amount.Add(itemAmount)
+
+			refund.Items = append(refund.Items, RefundItem{
+				TransactionItemID: item.ID,
+				ProductID:         item.ProductID,
+				Quantity:          quantity,
+				Amount:            itemAmount,
+			})
+		}
+
+		items, err := itemRepo.FindByTransactionID(transaction.ID)
+		if err != nil {
+			return err
+		}
+
+		fullyRefunded = true
+		for _, item := range items {
+			if item.RefundedQuantity < item.Quantity {
+				fullyRefunded = false
+				break
+			}
+		}
+
+		// refund terakhir mengambil sisa pembulatan supaya total refund
+		// sama persis dengan nominal yang dibayar
+		if fullyRefunded {
+			refunded, err := refundRepo.SumActiveAmount(transaction.ID)
+			if err != nil {
+				return err
+			}
+			amount = transaction.TotalAmount.Round(0).Sub(refunded)
+		}
+
+		refund.Amount = amount.Round(0)
+		if !refund.Amount.IsPositive() {
+			return ErrNothingToRefund
+		}
+
+		return refundRepo.Create(refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.gateway.Refund(transaction.OrderID, payment.RefundRequest{
+		RefundKey: refund.ID.String(),
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+	})
+	if err != nil {
+		if failErr := s.failRefund(refund, err.Error()); failErr != nil {
+			log.Printf("failed to roll back refund %s: %v", refund.ID, failErr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	newStatus := TransactionStatusPartiallyRefunded
+	if fullyRefunded {
+		newStatus = TransactionStatusRefunded
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.refundRepository.WithTx(tx).MarkSucceeded(refund.ID); err != nil {
+			return err
+		}
+
+		if _, err := s.transactionRepository.WithTx(tx).TransitionStatus(transaction.ID, refundableStatuses, newStatus, ""); err != nil {
+			return err
+		}
+
+		if !refund.Restock {
+			return nil
+		}
+
+		stockRepo := s.stockMovementRepo.WithTx(tx)
+		for _, item := range refund.Items {
+			if err := stockRepo.AddStockReturn(item.ProductID, item.Quantity, refund.ID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		// uang sudah dikembalikan gateway, record perlu dibetulkan manual
+		log.Printf("refund %s succeeded at gateway but failed to record: %v", refund.ID, err)
+		return nil, err
+	}
+
+	refund.Status = RefundStatusSucceeded
+
+	dto := toRefundDTO(refund)
+	return &dto, nil
+}
+
+// failRefund menandai refund gagal dan melepas jumlah item yang ditahan
+func (s *refundService) failRefund(refund *Refund, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.refundRepository.WithTx(tx).MarkFailed(refund.ID, reason); err != nil {
+			return err
+		}
+
+		itemRepo := NewTransactionItemRepository(tx)
+		for _, item := range refund.Items {
+			if _, err := itemRepo.AddRefundedQuantity(item.TransactionItemID, -item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *refundService) GetRefunds(transactionID uuid.UUID) ([]RefundDTO, error) {
+	refunds, err := s.refundRepository.FindByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]RefundDTO, 0, len(refunds))
+	for i := range refunds {
+		result = append(result, toRefundDTO(&refunds[i]))
+	}
+
+	return result, nil
+}
+
+// refundQuantities mengubah request menjadi jumlah per item transaksi.
+// Request tanpa items berarti semua sisa item di-refund
+func refundQuantities(items []TransactionItem, requested []RefundItemRequest) (map[uuid.UUID]int, error) {
+	remaining := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		remaining[item.ID] = item.Quantity - item.RefundedQuantity
+	}
+
+	quantities := make(map[uuid.UUID]int, len(items))
+
+	if len(requested) == 0 {
+		for id, quantity := range remaining {
+			if quantity > 0 {
+				quantities[id] = quantity
+			}
+		}
+	}
+
+	for _, req := range requested {
+		left, ok := remaining[req.TransactionItemID]
+		if !ok {
+			return nil, ErrInvalidRefundItem
+		}
+
+		quantities[req.TransactionItemID] += req.Quantity
+		if quantities[req.TransactionItemID] > left {
+			return nil, ErrRefundQuantityExceeded
+		}
+	}
+
+	if len(quantities) == 0 {
+		return nil, ErrNothingToRefund
+	}
+
+	return quantities, nil
+}
+
+func toRefundDTO(refund *Refund) RefundDTO {
+	items := make([]RefundItemDTO, 0, len(refund.Items))
+	for _, item := range refund.Items {
+		items = append(items, RefundItemDTO{
+			TransactionItemID: item.TransactionItemID,
+			ProductID:         item.ProductID,
+			Quantity:          item.Quantity,
+			Amount:            item.Amount,
+		})
+	}
+
+	return RefundDTO{
+		ID:            refund.ID,
+		TransactionID: refund.TransactionID,
+		Amount:        refund.Amount,
+		Reason:        refund.Reason,
+		Status:        string(refund.Status),
+		Restock:       refund.Restock,
+		FailureReason: refund.FailureReason,
+		Items:         items,
+		CreatedAt:     refund.CreatedAt,
+		CompletedAt:   refund.CompletedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package transactions
+
+import (
+	"errors"
+	"testing"
+
+	"go-fiber-api/internal/features/inventory"
+	"go-fiber-api/internal/util/payment"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestRefundQuantities(t *testing.T) {
+	first := TransactionItem{ID: uuid.New(), Quantity: 3, RefundedQuantity: 1}
+	second := TransactionItem{ID: uuid.New(), Quantity: 2, RefundedQuantity: 2}
+	items := []TransactionItem{first, second}
+
+	tests := []struct {
+		name      string
+		requested []RefundItemRequest
+		want      map[uuid.UUID]int
+		wantErr   error
+	}{
+		{name: "everything left", want: map[uuid.UUID]int{first.ID: 2}},
+		{name: "single item", requested: []RefundItemRequest{{TransactionItemID: first.ID, Quantity: 1}}, want: map[uuid.UUID]int{first.ID: 1}},
+		{name: "same item twice", requested: []RefundItemRequest{{TransactionItemID: first.ID, Quantity: 1}, {TransactionItemID: first.ID, Quantity: 1}}, want: map[uuid.UUID]int{first.ID: 2}},
+		{name: "more than left", requested: []RefundItemRequest{{TransactionItemID: first.ID, Quantity: 3}}, wantErr: ErrRefundQuantityExceeded},
+		{name: "duplicates exceed left", requested: []RefundItemRequest{{TransactionItemID: first.ID, Quantity: 2}, {TransactionItemID: first.ID, Quantity: 1}}, wantErr: ErrRefundQuantityExceeded},
+		{name: "fully refunded item", requested: []RefundItemRequest{{TransactionItemID: second.ID, Quantity: 1}}, wantErr: ErrRefundQuantityExceeded},
+		{name: "foreign item", requested: []RefundItemRequest{{TransactionItemID: uuid.New(), Quantity: 1}}, wantErr: ErrInvalidRefundItem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := refundQuantities(items, tt.requested)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("refundQuantities error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("refundQuantities: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("quantities = %v, want %v", got, tt.want)
+			}
+			for id, quantity := range tt.want {
+				if got[id] != quantity {
+					t.Fatalf("quantities = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	fullyRefunded := []TransactionItem{second}
+	if _, err := refundQuantities(fullyRefunded, nil); !errors.Is(err, ErrNothingToRefund) {
+		t.Fatalf("refundQuantities error = %v, want %v", err, ErrNothingToRefund)
+	}
+}
+
+// paid membuat transaksi yang sudah dibayar lewat notifikasi gateway
+func (f *paymentFixture) paid(t *testing.T, quantity int) *Transaction {
+	t.Helper()
+
+	transaction := f.checkout(t, quantity)
+
+	payload, err := f.gateway.Simulate(transaction.OrderID, payment.StatusPaid)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	if err := f.service.HandlePaymentNotification(payload, "127.0.0.1"); err != nil {
+		t.Fatalf("HandlePaymentNotification: %v", err)
+	}
+
+	detail, err := f.service.transactionRepository.GetTransactionsDetailByID(transaction.ID.String())
+	if err != nil {
+		t.Fatalf("GetTransactionsDetailByID: %v", err)
+	}
+
+	return detail
+}
+
+func (f *paymentFixture) refundService() RefundService {
+	return NewRefundService(
+		f.db,
+		NewTransactionRepository(f.db),
+		NewRefundRepository(f.db),
+		inventory.NewStockMovementRepository(f.db),
+		f.gateway,
+	)
+}
+
+func TestPartialThenFullRefund(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.paid(t, 3)
+	refunds := f.refundService()
+	item := transaction.Items[0]
+
+	partial, err := refunds.CreateRefund(transaction.ID, f.userID, &CreateRefundRequest{
+		Items:   []RefundItemRequest{{TransactionItemID: item.ID, Quantity: 1}},
+		Reason:  "damaged",
+		Restock: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateRefund partial: %v", err)
+	}
+
+	if !partial.Amount.Equal(decimal.NewFromInt(15000)) {
+		t.Errorf("partial refund amount = %s, want 15000", partial.Amount)
+	}
+
+	// item yang di-restock kembali ke stok
+	f.assertState(t, transaction.ID, TransactionStatusPartiallyRefunded, 3, 0)
+
+	if _, err := refunds.CreateRefund(transaction.ID, f.userID, &CreateRefundRequest{
+		Items:  []RefundItemRequest{{TransactionItemID: item.ID, Quantity: 3}},
+		Reason: "too many",
+	}); !errors.Is(err, ErrRefundQuantityExceeded) {
+		t.Fatalf("over refund error = %v, want %v", err, ErrRefundQuantityExceeded)
+	}
+
+	rest, err := refunds.CreateRefund(transaction.ID, f.userID, &CreateRefundRequest{Reason: "order cancelled"})
+	if err != nil {
+		t.Fatalf("CreateRefund rest: %v", err)
+	}
+
+	// total refund sama dengan nominal yang dibayar
+	if total := partial.Amount.Add(rest.Amount); !total.Equal(transaction.TotalAmount.Round(0)) {
+		t.Errorf("total refunded = %s, want %s", total, transaction.TotalAmount.Round(0))
+	}
+
+	// sisa item tidak di-restock
+	f.assertState(t, transaction.ID, TransactionStatusRefunded, 3, 0)
+
+	if _, err := refunds.CreateRefund(transaction.ID, f.userID, &CreateRefundRequest{Reason: "again"}); !errors.Is(err, ErrTransactionNotRefundable) {
+		t.Fatalf("refund of refunded transaction error = %v, want %v", err, ErrTransactionNotRefundable)
+	}
+}
+
+func TestRefundOfUnpaidTransactionIsRejected(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 1)
+
+	if _, err := f.refundService().CreateRefund(transaction.ID, f.userID, &CreateRefundRequest{Reason: "changed mind"}); !errors.Is(err, ErrTransactionNotRefundable) {
+		t.Fatalf("CreateRefund error = %v, want %v", err, ErrTransactionNotRefundable)
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusPending, 5, 1)
+}
@@ -25,12 +25,16 @@ type CreateTransactionResponse struct {
 	Status      string `json:"status"`
 }
 
-// MidtransNotificationRequest mewakili payload penting dari webhook Midtrans
-type MidtransNotificationRequest struct {
-	TransactionStatus string `json:"transaction_status"`
-	FraudStatus       string `json:"fraud_status"`
-	PaymentType       string `json:"payment_type"`
-	OrderID           string `json:"order_id"`
+// PaymentConfigResponse berisi konfigurasi publik untuk Snap di frontend,
+// server key tidak pernah ikut dikirim
+type PaymentConfigResponse struct {
+	Provider        string   `json:"provider"`
+	Environment     string   `json:"environment"`
+	ClientKey       string   `json:"client_key"`
+	MerchantID      string   `json:"merchant_id,omitempty"`
+	SnapJSURL       string   `json:"snap_js_url"`
+	EnabledPayments []string `json:"enabled_payments"`
+	ExpiryMinutes   int64    `json:"expiry_minutes,omitempty"`
 }
 
 // Response untuk detail transaksi beserta item-nya
@@ -42,12 +46,15 @@ type TransactionItemResponse struct {
 	Quantity    int             `json:"quantity"`
 	Price       decimal.Decimal `json:"price"`
 	Subtotal    decimal.Decimal `json:"subtotal"`
+
+	RefundedQuantity int `json:"refunded_quantity"`
 }
 
 type TransactionDetailResponse struct {
 	ID             uuid.UUID                 `json:"id"`
 	OrderID        string                    `json:"order_id"`
 	Status         string                    `json:"status"`
+	StatusReason   string                    `json:"status_reason,omitempty"`
 	TotalAmount    decimal.Decimal           `json:"total_amount"`
 	PaymentType    string                    `json:"payment_type"`
 	MerchantID     uuid.UUID                 `json:"merchant_id"`
@@ -55,6 +62,11 @@ type TransactionDetailResponse struct {
 	IdempotencyKey string                    `json:"idempotency_key"`
 	CreatedAt      time.Time                 `json:"created_at"`
 	Items          []TransactionItemResponse `json:"items"`
+
+	// FulfillmentStatus kosong untuk transaksi yang belum dibayar
+	FulfillmentStatus    string     `json:"fulfillment_status,omitempty"`
+	FulfillmentNote      string     `json:"fulfillment_note,omitempty"`
+	FulfillmentUpdatedAt *time.Time `json:"fulfillment_updated_at,omitempty"`
 }
 
 type TransactionDTO struct {
@@ -64,11 +76,59 @@ type TransactionDTO struct {
 	TotalAmount decimal.Decimal `json:"total_amount"`
 	PaymentType string          `json:"payment_type"`
 	MerchantID  uuid.UUID       `json:"merchant_id"`
-
-	CreatedAt time.Time `json:"created_at"`
+	// FulfillmentStatus dibaca langsung dari kolom, bisa kosong untuk
+	// transaksi lama yang belum diproses merchant
+	FulfillmentStatus string    `json:"fulfillment_status,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 type TransactionWithMerchant struct {
 	Transaction
 	MerchantName string `json:"merchant_name" gorm:"column:merchant_name"`
 }
+
+type RefundItemRequest struct {
+	TransactionItemID uuid.UUID `json:"transaction_item_id" validate:"required"`
+	Quantity          int       `json:"quantity" validate:"required,gt=0"`
+}
+
+// CreateRefundRequest tanpa items berarti refund semua item yang tersisa
+type CreateRefundRequest struct {
+	Items   []RefundItemRequest `json:"items" validate:"omitempty,dive"`
+	Reason  string              `json:"reason" validate:"required,max=255"`
+	Restock bool                `json:"restock"`
+}
+
+type RefundItemDTO struct {
+	TransactionItemID uuid.UUID       `json:"transaction_item_id"`
+	ProductID         uuid.UUID       `json:"product_id"`
+	Quantity          int             `json:"quantity"`
+	Amount            decimal.Decimal `json:"amount"`
+}
+
+type RefundDTO struct {
+	ID            uuid.UUID       `json:"id"`
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	Reason        string          `json:"reason"`
+	Status        string          `json:"status"`
+	Restock       bool            `json:"restock"`
+	FailureReason string          `json:"failure_reason,omitempty"`
+	Items         []RefundItemDTO `json:"items"`
+	CreatedAt     time.Time       `json:"created_at"`
+	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+}
+
+type AdvanceFulfillmentRequest struct {
+	Status FulfillmentStatus `json:"status" validate:"required"`
+	// Note berisi nomor resi saat SHIPPED, wajib diisi alasan saat REJECTED
+	Note string `json:"note" validate:"max=255"`
+}
+
+type FulfillmentResponse struct {
+	TransactionID     uuid.UUID  `json:"transaction_id"`
+	Status            string     `json:"status"`
+	FulfillmentStatus string     `json:"fulfillment_status"`
+	FulfillmentNote   string     `json:"fulfillment_note,omitempty"`
+	Refund            *RefundDTO `json:"refund,omitempty"`
+}
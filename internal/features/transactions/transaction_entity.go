@@ -14,8 +14,33 @@ type TransactionStatus string
 
 const (
 	TransactionStatusPending TransactionStatus = "PENDING"
-	TransactionStatusPaid    TransactionStatus = "PAID"
-	TransactionStatusFailed  TransactionStatus = "FAILED"
+	// TransactionStatusChallenge: pembayaran ditandai fraud_status=challenge
+	// oleh Midtrans dan menunggu review merchant/admin
+	TransactionStatusChallenge TransactionStatus = "CHALLENGE"
+	TransactionStatusPaid      TransactionStatus = "PAID"
+	TransactionStatusFailed    TransactionStatus = "FAILED"
+	// TransactionStatusCancelled hanya untuk pembatalan oleh pembeli,
+	// pembayaran yang gagal/kedaluwarsa di gateway tetap FAILED
+	TransactionStatusCancelled TransactionStatus = "CANCELLED"
+	// status setelah PAID, lihat RefundService
+	TransactionStatusRefunded          TransactionStatus = "REFUNDED"
+	TransactionStatusPartiallyRefunded TransactionStatus = "PARTIALLY_REFUNDED"
+)
+
+// FulfillmentStatus adalah proses pesanan setelah dibayar, terpisah dari
+// status pembayaran. Kosong di database berarti menunggu diterima merchant
+type FulfillmentStatus string
+
+const (
+	FulfillmentAwaitingAcceptance FulfillmentStatus = "AWAITING_ACCEPTANCE"
+	FulfillmentAccepted           FulfillmentStatus = "ACCEPTED"
+	FulfillmentPreparing          FulfillmentStatus = "PREPARING"
+	FulfillmentReadyForPickup     FulfillmentStatus = "READY_FOR_PICKUP"
+	FulfillmentShipped            FulfillmentStatus = "SHIPPED"
+	FulfillmentDelivered          FulfillmentStatus = "DELIVERED"
+	FulfillmentCompleted          FulfillmentStatus = "COMPLETED"
+	// FulfillmentRejected otomatis me-refund seluruh sisa pembayaran
+	FulfillmentRejected FulfillmentStatus = "REJECTED"
 )
 
 type Transaction struct {
@@ -27,17 +52,42 @@ type Transaction struct {
 	// IdempotencyKey digunakan untuk mencegah duplikasi payment/order
 	IdempotencyKey string `gorm:"type:varchar(100);not null;uniqueIndex"`
 
-	Status      TransactionStatus `gorm:"type:varchar(50);not null;default:'PENDING'"`
+	Status      TransactionStatus `gorm:"type:varchar(50);not null;default:'PENDING';index:idx_transactions_status_created_at,priority:1"`
 	TotalAmount decimal.Decimal   `gorm:"type:decimal(18,2);not null"`
 	PaymentType string            `gorm:"type:varchar(50)"`
 	SnapToken   string            `gorm:"type:text"`
 	RedirectURL string            `gorm:"type:text"`
 
+	// StatusReason menjelaskan kenapa transaksi gagal, misalnya kedaluwarsa
+	StatusReason string `gorm:"type:varchar(255)"`
+
+	FulfillmentStatus    FulfillmentStatus `gorm:"type:varchar(30);not null;default:''"`
+	FulfillmentNote      string            `gorm:"type:varchar(255)"`
+	FulfillmentUpdatedAt *time.Time
+
+	// diisi saat transaksi CHALLENGE di-approve/deny
+	ReviewedBy *uuid.UUID `gorm:"type:uuid"`
+	ReviewedAt *time.Time
+
 	// Relations
 	User     auth.User         `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Merchant merchant.Merchant `gorm:"foreignKey:MerchantID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Items    []TransactionItem `gorm:"foreignKey:TransactionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 
-	CreatedAt time.Time
+	CreatedAt time.Time `gorm:"index:idx_transactions_status_created_at,priority:2"`
 	UpdatedAt time.Time
 }
+
+// CurrentFulfillment mengembalikan status fulfillment yang ditampilkan,
+// transaksi yang belum dibayar tidak punya status fulfillment
+func (t Transaction) CurrentFulfillment() FulfillmentStatus {
+	if t.FulfillmentStatus != "" {
+		return t.FulfillmentStatus
+	}
+
+	if t.Status == TransactionStatusPaid || t.Status == TransactionStatusPartiallyRefunded {
+		return FulfillmentAwaitingAcceptance
+	}
+
+	return ""
+}
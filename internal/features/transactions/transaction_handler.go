@@ -1,15 +1,17 @@
 package transactions
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
 	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/features/inventory"
+	"go-fiber-api/internal/util/payment"
 	"go-fiber-api/internal/util/token"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/midtrans/midtrans-go"
 	"gorm.io/gorm"
 )
 
@@ -18,10 +20,16 @@ type transactionHandler struct {
 }
 type TransactionHandler interface {
 	CreateTransaction(c *fiber.Ctx) error
-	HandleMidtransWebhook(c *fiber.Ctx) error
+	HandlePaymentWebhook(c *fiber.Ctx) error
+	SimulatePayment(c *fiber.Ctx) error
 	GetTransactionDetail(c *fiber.Ctx) error
 	GetTransactionsByUserID(c *fiber.Ctx) error
 	ResumeTransaction(c *fiber.Ctx) error
+	GetChallengedTransactions(c *fiber.Ctx) error
+	GetMerchantChallengedTransactions(c *fiber.Ctx) error
+	ApproveChallenge(c *fiber.Ctx) error
+	DenyChallenge(c *fiber.Ctx) error
+	CancelTransaction(c *fiber.Ctx) error
 }
 
 func NewTransactionHandler(service TransactionService) *transactionHandler {
@@ -45,11 +53,8 @@ func (h *transactionHandler) CreateTransaction(c *fiber.Ctx) error {
 
 	result, err := h.service.CreateTransaction(claims.UserID, &req)
 	if err != nil {
-		// Midtrans library kadang mengembalikan error interface dengan
-		// dynamic type *midtrans.Error namun pointer-nya nil, sehingga
-		// pemanggilan err.Error() akan menyebabkan panic (nil receiver).
-		if midErr, ok := err.(*midtrans.Error); ok && midErr == nil {
-			return response.Fail(c, http.StatusBadRequest, "payment gateway error")
+		if errors.Is(err, inventory.ErrInsufficientStock) {
+			return response.Fail(c, http.StatusConflict, err.Error())
 		}
 
 		return response.Fail(c, http.StatusBadRequest, err.Error())
@@ -58,21 +63,43 @@ func (h *transactionHandler) CreateTransaction(c *fiber.Ctx) error {
 	return response.Success(c, "transaction cd", result)
 }
 
-func (h *transactionHandler) HandleMidtransWebhook(c *fiber.Ctx) error {
+func (h *transactionHandler) HandlePaymentWebhook(c *fiber.Ctx) error {
+	if err := h.service.HandlePaymentNotification(c.Body(), c.IP()); err != nil {
+		log.Println("webhook error:", err)
 
-	var notification MidtransNotificationRequest
-	if err := c.BodyParser(&notification); err != nil {
-		log.Println("invalid payload:", err)
-		return c.SendStatus(http.StatusOK)
+		// notifikasi yang ditolak sudah dicatat, tetap 200 supaya tidak
+		// dikirim ulang. Error lain (database, status API) dibalas 500
+		// supaya provider mencoba lagi
+		if errors.Is(err, ErrNotificationRejected) {
+			return c.SendStatus(http.StatusOK)
+		}
+		return c.SendStatus(http.StatusInternalServerError)
 	}
 
-	if err := h.service.HandleMidtransWebhook(&notification); err != nil {
-		log.Println("webhook error:", err)
-		// tetap 200
-		return c.SendStatus(http.StatusOK)
+	return c.SendStatus(http.StatusOK)
+}
+
+// SimulatePayment hanya terdaftar kalau PAYMENT_DRIVER=fake
+func (h *transactionHandler) SimulatePayment(c *fiber.Ctx) error {
+	transactionID, err := uuid.Parse(c.Params("transaction_id"))
+	if err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid transaction id format")
 	}
 
-	return c.SendStatus(http.StatusOK)
+	if err := h.service.SimulatePayment(transactionID, c.Params("status"), c.IP()); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, payment.ErrOrderNotFound):
+			return response.Fail(c, http.StatusNotFound, "transaction not found")
+		case errors.Is(err, payment.ErrInvalidTransition):
+			return response.Fail(c, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrSimulationUnavailable), errors.Is(err, ErrNotificationRejected):
+			return response.Fail(c, http.StatusBadRequest, err.Error())
+		default:
+			return response.Fail(c, http.StatusInternalServerError, "failed to simulate payment")
+		}
+	}
+
+	return response.SuccessNoData(c, "payment simulated")
 }
 
 func (h *transactionHandler) GetTransactionDetail(c *fiber.Ctx) error {
@@ -111,7 +138,9 @@ func (h *transactionHandler) ResumeTransaction(c *fiber.Ctx) error {
 		return response.Fail(c, http.StatusBadRequest, "idempotency_key is required")
 	}
 
-	result, err := h.service.ResumeTransactionByIdempotencyKey(IdempotencyKey)
+	user_id := c.Locals("user_id").(*token.CustomClaims).UserID
+
+	result, err := h.service.ResumeTransactionByIdempotencyKey(user_id, IdempotencyKey)
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -146,3 +175,100 @@ func (h *transactionHandler) GetTransactionsByMerchantID(c *fiber.Ctx) error {
 
 	return response.Success(c, "transaction history", result)
 }
+
+func (h *transactionHandler) GetChallengedTransactions(c *fiber.Ctx) error {
+	result, err := h.service.GetChallengedTransactions(nil)
+	if err != nil {
+		return response.Fail(c, http.StatusInternalServerError, "failed to get review queue")
+	}
+
+	return response.Success(c, "review queue", result)
+}
+
+func (h *transactionHandler) GetMerchantChallengedTransactions(c *fiber.Ctx) error {
+	merchantID, err := uuid.Parse(c.Params("merchant_id"))
+	if err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid merchant id format")
+	}
+
+	result, err := h.service.GetChallengedTransactions(&merchantID)
+	if err != nil {
+		return response.Fail(c, http.StatusInternalServerError, "failed to get review queue")
+	}
+
+	return response.Success(c, "review queue", result)
+}
+
+func (h *transactionHandler) ApproveChallenge(c *fiber.Ctx) error {
+	return h.reviewChallenge(c, true)
+}
+
+func (h *transactionHandler) DenyChallenge(c *fiber.Ctx) error {
+	return h.reviewChallenge(c, false)
+}
+
+func (h *transactionHandler) CancelTransaction(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, http.StatusUnauthorized, "unauthorized")
+	}
+
+	transactionID, err := uuid.Parse(c.Params("transaction_id"))
+	if err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	result, err := h.service.CancelTransaction(transactionID, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.Fail(c, http.StatusNotFound, "transaction not found")
+		case errors.Is(err, ErrTransactionNotCancellable):
+			return response.Fail(c, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrCancelFailed):
+			log.Println("cancel transaction error:", err)
+			return response.Fail(c, http.StatusBadGateway, "failed to cancel payment")
+		default:
+			log.Println("cancel transaction error:", err)
+			return response.Fail(c, http.StatusInternalServerError, "failed to cancel transaction")
+		}
+	}
+
+	return response.Success(c, "transaction cancelled", result)
+}
+
+func (h *transactionHandler) reviewChallenge(c *fiber.Ctx, approve bool) error {
+	claims, ok := c.Locals("user_id").(*token.CustomClaims)
+	if !ok || claims == nil {
+		return response.Fail(c, http.StatusUnauthorized, "unauthorized")
+	}
+
+	transactionID, err := uuid.Parse(c.Params("transaction_id"))
+	if err != nil {
+		return response.Fail(c, http.StatusBadRequest, "invalid transaction id format")
+	}
+
+	var result *TransactionDTO
+	if approve {
+		result, err = h.service.ApproveChallenge(transactionID, claims.UserID)
+	} else {
+		result, err = h.service.DenyChallenge(transactionID, claims.UserID)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.Fail(c, http.StatusNotFound, "transaction not found")
+		case errors.Is(err, ErrTransactionNotChallenged):
+			return response.Fail(c, http.StatusConflict, err.Error())
+		default:
+			log.Println("review challenge error:", err)
+			return response.Fail(c, http.StatusBadGateway, "failed to review payment")
+		}
+	}
+
+	if approve {
+		return response.Success(c, "payment approved", result)
+	}
+	return response.Success(c, "payment denied", result)
+}
@@ -16,6 +16,9 @@ type TransactionItem struct {
 	Price    decimal.Decimal `gorm:"type:decimal(10,2);not null"`
 	Subtotal decimal.Decimal `gorm:"type:decimal(18,2);not null"`
 
+	// RefundedQuantity mencegah item yang sama di-refund melebihi jumlah beli
+	RefundedQuantity int `gorm:"type:int;not null;default:0"`
+
 	// Relations
 	Transaction Transaction      `gorm:"foreignKey:TransactionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Product     products.Product `gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
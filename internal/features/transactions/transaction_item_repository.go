@@ -8,6 +8,7 @@ import (
 type TransactionItemRepository interface {
 	BulkCreate(items []TransactionItem) error
 	FindByTransactionID(txID uuid.UUID) ([]TransactionItem, error)
+	AddRefundedQuantity(itemID uuid.UUID, quantity int) (bool, error)
 }
 
 type transactionItemRepository struct {
@@ -34,3 +35,18 @@ func (r *transactionItemRepository) FindByTransactionID(txID uuid.UUID) ([]Trans
 	}
 	return items, nil
 }
+
+// AddRefundedQuantity menambah (atau mengurangi kalau negatif) jumlah item
+// yang di-refund, false kalau hasilnya melebihi jumlah beli
+func (r *transactionItemRepository) AddRefundedQuantity(itemID uuid.UUID, quantity int) (bool, error) {
+	result := r.db.
+		Model(&TransactionItem{}).
+		Where("id = ? AND refunded_quantity + ? BETWEEN 0 AND quantity", itemID, quantity).
+		Update("refunded_quantity", gorm.Expr("refunded_quantity + ?", quantity))
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
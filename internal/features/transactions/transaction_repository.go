@@ -1,7 +1,7 @@
 package transactions
 
 import (
-	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -9,9 +9,15 @@ import (
 
 type TransactionRepository interface {
 	Create(trx *Transaction) error
+	FindByID(id uuid.UUID) (*Transaction, error)
 	FindByOrderID(orderID string) (*Transaction, error)
 	FindByIdempotencyKey(key string) (*Transaction, error)
-	UpdateStatusAndPaymentType(orderID string, status TransactionStatus, paymentType string) error
+	TransitionStatus(id uuid.UUID, from []TransactionStatus, to TransactionStatus, paymentType string) (bool, error)
+	MarkReviewed(id uuid.UUID, reviewerID uuid.UUID) error
+	SetStatusReason(id uuid.UUID, reason string) error
+	TransitionFulfillment(id uuid.UUID, from []FulfillmentStatus, to FulfillmentStatus, note string) (bool, error)
+	FindExpiredPending(createdBefore time.Time, limit int) ([]Transaction, error)
+	GetChallengedTransactions(merchantID *uuid.UUID) ([]TransactionDTO, error)
 	GetTransactionsByUserID(userID uuid.UUID) ([]TransactionWithMerchant, error)
 	GetTransactionsDetailByID(orderID string) (*Transaction, error)
 	GetTransactionByMerchantID(MerchantID uuid.UUID) ([]TransactionDTO, error)
@@ -35,6 +41,15 @@ func (r *transactionRepository) Create(trx *Transaction) error {
 	return result.Error
 }
 
+func (r *transactionRepository) FindByID(id uuid.UUID) (*Transaction, error) {
+	var trx Transaction
+	result := r.db.Where("id = ?", id).First(&trx)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &trx, nil
+}
+
 func (r *transactionRepository) FindByOrderID(orderID string) (*Transaction, error) {
 	var trx Transaction
 	result := r.db.
@@ -56,31 +71,126 @@ func (r *transactionRepository) FindByIdempotencyKey(key string) (*Transaction,
 	return &trx, nil
 }
 
-func (r *transactionRepository) UpdateStatusAndPaymentType(
-	orderID string,
-	status TransactionStatus,
+// TransitionStatus hanya mengubah status kalau status saat ini ada di from,
+// false berarti transaksi sudah dipindahkan oleh request lain
+func (r *transactionRepository) TransitionStatus(
+	id uuid.UUID,
+	from []TransactionStatus,
+	to TransactionStatus,
 	paymentType string,
-) error {
+) (bool, error) {
 
 	updates := map[string]interface{}{
-		"status":       status,
-		"payment_type": paymentType,
+		"status": to,
+	}
+
+	if paymentType != "" {
+		updates["payment_type"] = paymentType
 	}
 
 	result := r.db.
 		Model(&Transaction{}).
-		Where("order_id = ?", orderID).
+		Where("id = ? AND status IN ?", id, from).
 		Updates(updates)
 
 	if result.Error != nil {
-		return result.Error
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *transactionRepository) MarkReviewed(id uuid.UUID, reviewerID uuid.UUID) error {
+	return r.db.
+		Model(&Transaction{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"reviewed_by": reviewerID,
+			"reviewed_at": time.Now().UTC(),
+		}).
+		Error
+}
+
+func (r *transactionRepository) SetStatusReason(id uuid.UUID, reason string) error {
+	return r.db.
+		Model(&Transaction{}).
+		Where("id = ?", id).
+		Update("status_reason", reason).
+		Error
+}
+
+// FindExpiredPending mengambil transaksi PENDING yang dibuat sebelum
+// createdBefore, yang paling lama lebih dulu
+func (r *transactionRepository) FindExpiredPending(createdBefore time.Time, limit int) ([]Transaction, error) {
+	var transactions []Transaction
+
+	err := r.db.
+		Preload("Items").
+		Where("status = ? AND created_at < ?", TransactionStatusPending, createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&transactions).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// TransitionFulfillment hanya berlaku untuk transaksi yang sudah dibayar.
+// Transaksi yang belum pernah diproses merchant kolomnya masih kosong,
+// jadi dianggap AWAITING_ACCEPTANCE
+func (r *transactionRepository) TransitionFulfillment(
+	id uuid.UUID,
+	from []FulfillmentStatus,
+	to FulfillmentStatus,
+	note string,
+) (bool, error) {
+
+	statuses := append([]FulfillmentStatus{}, from...)
+	for _, status := range from {
+		if status == FulfillmentAwaitingAcceptance {
+			statuses = append(statuses, "")
+			break
+		}
+	}
+
+	result := r.db.
+		Model(&Transaction{}).
+		Where("id = ? AND status IN ? AND fulfillment_status IN ?", id, refundableStatuses, statuses).
+		Updates(map[string]interface{}{
+			"fulfillment_status":     to,
+			"fulfillment_note":       note,
+			"fulfillment_updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return false, result.Error
 	}
 
-	if result.RowsAffected == 0 {
-		return errors.New("transaction not found for order_id")
+	return result.RowsAffected > 0, nil
+}
+
+// GetChallengedTransactions mengembalikan antrian review, merchantID nil
+// berarti semua merchant (untuk admin)
+func (r *transactionRepository) GetChallengedTransactions(merchantID *uuid.UUID) ([]TransactionDTO, error) {
+	var transactions []TransactionDTO
+
+	query := r.db.
+		Table("transactions").
+		Where("status = ?", TransactionStatusChallenge)
+
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+
+	err := query.Order("created_at ASC").Find(&transactions).Error
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return transactions, nil
 }
 
 func (r *transactionRepository) GetTransactionsByUserID(userID uuid.UUID) ([]TransactionWithMerchant, error) {
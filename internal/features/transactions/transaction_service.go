@@ -3,25 +3,42 @@ package transactions
 import (
 	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"time"
 
-	"go-fiber-api/internal/config"
 	"go-fiber-api/internal/features/inventory"
 	"go-fiber-api/internal/features/products"
+	"go-fiber-api/internal/util/payment"
 
 	"github.com/google/uuid"
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/snap"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
+// ErrNotificationRejected dipakai untuk notifikasi webhook yang tidak valid,
+// provider tidak perlu mengirim ulang notifikasi seperti ini
+var (
+	ErrNotificationRejected      = errors.New("payment notification rejected")
+	ErrTransactionNotChallenged  = errors.New("transaction is not waiting for review")
+	ErrSimulationUnavailable     = errors.New("payment simulation is only available with the fake gateway")
+	ErrTransactionNotCancellable = errors.New("only pending transactions can be cancelled")
+	ErrCancelFailed              = errors.New("payment gateway cancel failed")
+)
+
 type TransactionService interface {
 	CreateTransaction(userID uuid.UUID, req *CreateTransactionRequest) (*CreateTransactionResponse, error)
-	HandleMidtransWebhook(req *MidtransNotificationRequest) error
+	HandlePaymentNotification(payload []byte, ipAddress string) error
+	SimulatePayment(transactionID uuid.UUID, status string, ipAddress string) error
+	ExpirePendingTransactions(ttl time.Duration, limit int) (int, error)
+	CancelTransaction(transactionID uuid.UUID, userID uuid.UUID) (*TransactionDTO, error)
 	GetTransactionDetail(transactionID string) (*TransactionDetailResponse, error)
 	GetTransactionsByUserID(userID uuid.UUID) ([]TransactionDetailResponse, error)
-	ResumeTransactionByIdempotencyKey(idempotencyKey string) (*CreateTransactionResponse, error)
+	ResumeTransactionByIdempotencyKey(userID uuid.UUID, idempotencyKey string) (*CreateTransactionResponse, error)
 	GetTransactionsByMerchantID(merchantID uuid.UUID) ([]TransactionDTO, error)
+	GetChallengedTransactions(merchantID *uuid.UUID) ([]TransactionDTO, error)
+	ApproveChallenge(transactionID uuid.UUID, reviewerID uuid.UUID) (*TransactionDTO, error)
+	DenyChallenge(transactionID uuid.UUID, reviewerID uuid.UUID) (*TransactionDTO, error)
 }
 
 type transactionService struct {
@@ -30,6 +47,9 @@ type transactionService struct {
 	itemRepository        TransactionItemRepository
 	productRepository     products.ProductRepository
 	stockMovementRepo     inventory.StockMovementRepository
+	reservationRepo       inventory.StockReservationRepository
+	notificationRepo      PaymentNotificationRepository
+	gateway               payment.PaymentGateway
 }
 
 func NewTransactionService(
@@ -38,6 +58,9 @@ func NewTransactionService(
 	itemRepo TransactionItemRepository,
 	productRepo products.ProductRepository,
 	stockMovementRepo inventory.StockMovementRepository,
+	reservationRepo inventory.StockReservationRepository,
+	notificationRepo PaymentNotificationRepository,
+	gateway payment.PaymentGateway,
 ) TransactionService {
 	return &transactionService{
 		db:                    db,
@@ -45,20 +68,53 @@ func NewTransactionService(
 		itemRepository:        itemRepo,
 		productRepository:     productRepo,
 		stockMovementRepo:     stockMovementRepo,
+		reservationRepo:       reservationRepo,
+		notificationRepo:      notificationRepo,
+		gateway:               gateway,
 	}
 }
 
-func mapMidtransStatus(mtStatus string) TransactionStatus {
-	switch mtStatus {
-	case "settlement", "capture":
+// toTransactionStatus memetakan status gateway ke status transaksi.
+// Ditolak, kedaluwarsa dan dibatalkan sama-sama dianggap gagal
+func toTransactionStatus(status payment.Status) TransactionStatus {
+	switch status {
+	case payment.StatusPaid:
 		return TransactionStatusPaid
-	case "cancel", "expire", "deny":
+	case payment.StatusChallenge:
+		return TransactionStatusChallenge
+	case payment.StatusDenied, payment.StatusExpired, payment.StatusCancelled:
 		return TransactionStatusFailed
 	default:
 		return TransactionStatusPending
 	}
 }
 
+// openStatuses adalah status yang masih boleh berubah
+var openStatuses = []TransactionStatus{TransactionStatusPending, TransactionStatusChallenge}
+
+func isOpenStatus(status TransactionStatus) bool {
+	for _, open := range openStatuses {
+		if status == open {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionSources mengembalikan status asal yang boleh pindah ke to.
+// Tidak ada yang boleh kembali ke PENDING, jadi notifikasi "pending" yang
+// telat untuk transaksi CHALLENGE tidak mengeluarkannya dari antrian review
+func transitionSources(to TransactionStatus) []TransactionStatus {
+	switch to {
+	case TransactionStatusPending:
+		return nil
+	case TransactionStatusChallenge, TransactionStatusCancelled:
+		return []TransactionStatus{TransactionStatusPending}
+	default:
+		return openStatuses
+	}
+}
+
 func (s *transactionService) CreateTransaction(userID uuid.UUID, req *CreateTransactionRequest) (*CreateTransactionResponse, error) {
 	if req.IdempotencyKey == "" {
 		return nil, fmt.Errorf("idempotency_key is required")
@@ -70,6 +126,10 @@ func (s *transactionService) CreateTransaction(userID uuid.UUID, req *CreateTran
 		return nil, err
 	}
 	if existing != nil && existing.ID != uuid.Nil {
+		if existing.UserID != userID {
+			return nil, fmt.Errorf("idempotency_key already used")
+		}
+
 		// Jika transaksi dengan idempotency_key ini sudah ada,
 		// selalu kembalikan informasi transaksi yang sama
 		if existing.SnapToken == "" || existing.RedirectURL == "" {
@@ -114,7 +174,7 @@ func (s *transactionService) CreateTransaction(userID uuid.UUID, req *CreateTran
 	orderID := fmt.Sprintf("ORDER-%s", uuid.NewString())
 	totalAmount := decimal.NewFromInt(0)
 	transactionItems := make([]TransactionItem, 0, len(req.Items))
-	itemDetails := make([]midtrans.ItemDetails, 0, len(req.Items))
+	chargeItems := make([]payment.Item, 0, len(req.Items))
 
 	for _, itemReq := range req.Items {
 		product, ok := productMap[itemReq.ProductID]
@@ -131,6 +191,11 @@ func (s *transactionService) CreateTransaction(userID uuid.UUID, req *CreateTran
 			return nil, fmt.Errorf("invalid product price")
 		}
 
+		// cek awal supaya pesan error jelas, kepastiannya tetap di Reserve
+		if product.AvailableQuantity() < itemReq.Quantity {
+			return nil, fmt.Errorf("%w: %s", inventory.ErrInsufficientStock, product.Name)
+		}
+
 		qtyDec := decimal.NewFromInt(int64(itemReq.Quantity))
 		subtotal := priceDecimal.Mul(qtyDec)
 		totalAmount = totalAmount.Add(subtotal)
@@ -142,12 +207,11 @@ func (s *transactionService) CreateTransaction(userID uuid.UUID, req *CreateTran
 			Subtotal:  subtotal,
 		})
 
-		itemDetails = append(itemDetails, midtrans.ItemDetails{
-			ID:   product.ID.String(),
-			Name: product.Name,
-			// Midtrans tetap butuh integer rupiah, pakai nilai dibulatkan
-			Price: priceDecimal.Round(0).IntPart(),
-			Qty:   int32(itemReq.Quantity),
+		chargeItems = append(chargeItems, payment.Item{
+			ID:       product.ID.String(),
+			Name:     product.Name,
+			Price:    priceDecimal,
+			Quantity: itemReq.Quantity,
 		})
 	}
 
@@ -180,53 +244,42 @@ func (s *transactionService) CreateTransaction(userID uuid.UUID, req *CreateTran
 			return err
 		}
 
-		return nil
+		return s.reserveStock(s.reservationRepo.WithTx(tx), transaction.ID, transactionItems)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := config.Get()
-	var snapClient snap.Client
-	snapClient.New(cfg.MidtransServerKey, midtrans.Sandbox)
-
-	snapReq := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID: transaction.OrderID,
-			// GrossAmt harus integer rupiah untuk Midtrans
-			GrossAmt: transaction.TotalAmount.Round(0).IntPart(),
-		},
-		Items: &itemDetails,
-	}
-
-	snapResp, err := snapClient.CreateTransaction(snapReq)
-
-	// Library Midtrans kadang mengembalikan error interface namun HTTP 200
-	// dan body berisi token. Jika snapResp tidak nil dan ada token,
-	// kita anggap sukses dan abaikan err untuk menghindari bug error nil-pointer.
-	if snapResp == nil {
-		// Jika tidak ada response sama sekali, baru error kita propagasi.
-		if err != nil {
-			return nil, err
+	charge, err := s.gateway.CreateCharge(payment.ChargeRequest{
+		OrderID:     transaction.OrderID,
+		GrossAmount: transaction.TotalAmount,
+		Items:       chargeItems,
+		CustomerID:  transaction.UserID.String(),
+	})
+	if err != nil {
+		// transaksi tanpa pembayaran tidak boleh terus menahan stok
+		transaction.Items = transactionItems
+		if _, failErr := s.applyStatus(transaction, TransactionStatusFailed, "", nil, "payment gateway charge failed"); failErr != nil {
+			log.Println("failed to release stock after charge error:", failErr)
 		}
-		return nil, fmt.Errorf("failed to create snap transaction: empty response from Midtrans")
+		return nil, err
 	}
 
 	// Simpan token dan redirect URL ke dalam record transaksi
 	updateErr := s.db.Model(&Transaction{}).
 		Where("id = ?", transaction.ID).
 		Updates(map[string]interface{}{
-			"snap_token":   snapResp.Token,
-			"redirect_url": snapResp.RedirectURL,
+			"snap_token":   charge.Token,
+			"redirect_url": charge.RedirectURL,
 		}).Error
 
 	if updateErr != nil {
 		return nil, updateErr
 	}
 
-	transaction.SnapToken = snapResp.Token
-	transaction.RedirectURL = snapResp.RedirectURL
+	transaction.SnapToken = charge.Token
+	transaction.RedirectURL = charge.RedirectURL
 
 	response := &CreateTransactionResponse{
 		OrderID:     transaction.OrderID,
@@ -238,49 +291,371 @@ func (s *transactionService) CreateTransaction(userID uuid.UUID, req *CreateTran
 	return response, nil
 }
 
-func (s *transactionService) HandleMidtransWebhook(
-	req *MidtransNotificationRequest,
+// reserveStock menahan stok tiap item dalam db transaction yang sama dengan
+// pembuatan transaksi. Urutan product_id dibuat tetap supaya dua checkout
+// dengan produk yang sama tidak saling deadlock
+func (s *transactionService) reserveStock(
+	reservationRepo inventory.StockReservationRepository,
+	transactionID uuid.UUID,
+	items []TransactionItem,
 ) error {
+	sorted := make([]TransactionItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ProductID.String() < sorted[j].ProductID.String()
+	})
+
+	for _, item := range sorted {
+		if err := reservationRepo.Reserve(transactionID, item.ProductID, item.Quantity); err != nil {
+			if errors.Is(err, inventory.ErrInsufficientStock) {
+				return fmt.Errorf("%w: product %s", inventory.ErrInsufficientStock, item.ProductID)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
 
-	if req.OrderID == "" {
-		return errors.New("order_id is required")
+// HandlePaymentNotification memproses webhook dari payment gateway.
+// Signature dan status provider diverifikasi oleh gateway, di sini
+// dicocokkan dengan transaksi yang tersimpan
+func (s *transactionService) HandlePaymentNotification(payload []byte, ipAddress string) error {
+	notification, err := s.gateway.ParseNotification(payload)
+	if errors.Is(err, payment.ErrInvalidNotification) {
+		return s.rejectNotification(notification, payload, ipAddress, err.Error())
+	}
+	if err != nil {
+		return err
 	}
 
-	transaction, err := s.transactionRepository.FindByOrderID(req.OrderID)
+	transaction, err := s.transactionRepository.FindByOrderID(notification.OrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.rejectNotification(notification, payload, ipAddress, "unknown order_id")
+	}
 	if err != nil {
 		return err
 	}
 
-	// ⛔ Jangan overwrite status final
-	if transaction.Status == TransactionStatusPaid ||
-		transaction.Status == TransactionStatusFailed {
+	// nominal yang dikirim ke gateway adalah TotalAmount dibulatkan ke rupiah
+	if !notification.GrossAmount.Equal(transaction.TotalAmount.Round(0)) {
+		return s.rejectNotification(notification, payload, ipAddress, "gross_amount does not match transaction total")
+	}
+
+	// ⛔ Jangan overwrite status final (termasuk notifikasi refund untuk
+	// transaksi yang sudah PAID, refund dicatat oleh RefundService)
+	if !isOpenStatus(transaction.Status) {
 		return nil
 	}
 
-	newStatus := mapMidtransStatus(req.TransactionStatus)
+	newStatus := toTransactionStatus(notification.Status)
 
-	return s.db.Transaction(func(dbTx *gorm.DB) error {
+	var reason string
+	if newStatus == TransactionStatusFailed {
+		reason = "payment " + string(notification.Status)
+	}
+
+	_, err = s.applyStatus(transaction, newStatus, notification.PaymentType, nil, reason)
+	return err
+}
+
+// SimulatePayment hanya untuk development: fake gateway mengubah status
+// lalu notifikasinya diproses lewat jalur yang sama dengan webhook
+func (s *transactionService) SimulatePayment(transactionID uuid.UUID, status string, ipAddress string) error {
+	simulator, ok := s.gateway.(payment.Simulator)
+	if !ok {
+		return ErrSimulationUnavailable
+	}
+
+	transaction, err := s.transactionRepository.GetTransactionsDetailByID(transactionID.String())
+	if err != nil {
+		return err
+	}
+
+	payload, err := simulator.Simulate(transaction.OrderID, payment.Status(status))
+	if err != nil {
+		return err
+	}
+
+	return s.HandlePaymentNotification(payload, ipAddress)
+}
+
+// ExpirePendingTransactions membatalkan transaksi PENDING yang lebih tua
+// dari ttl di gateway lalu menandainya FAILED. Kegagalan satu transaksi
+// hanya di-log supaya sisanya tetap diproses
+func (s *transactionService) ExpirePendingTransactions(ttl time.Duration, limit int) (int, error) {
+	pending, err := s.transactionRepository.FindExpiredPending(time.Now().Add(-ttl), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range pending {
+		ok, err := s.expireTransaction(&pending[i], ttl)
+		if err != nil {
+			log.Printf("failed to expire transaction %s: %v", pending[i].OrderID, err)
+			continue
+		}
+
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func (s *transactionService) expireTransaction(transaction *Transaction, ttl time.Duration) (bool, error) {
+	result, err := s.gateway.Cancel(transaction.OrderID)
+
+	// order yang tidak dikenal gateway berarti pembeli tidak pernah
+	// membuka halaman pembayaran, cukup dibatalkan di sisi kita
+	if err != nil && !errors.Is(err, payment.ErrOrderNotFound) {
+		// cancel bisa ditolak karena pembayaran ternyata sudah masuk,
+		// pakai status terbaru dari gateway
+		result, err = s.gateway.GetStatus(transaction.OrderID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if result != nil {
+		switch newStatus := toTransactionStatus(result.Status); newStatus {
+		case TransactionStatusPending:
+			// coba lagi di putaran berikutnya
+			return false, nil
+		case TransactionStatusPaid, TransactionStatusChallenge:
+			_, err := s.applyStatus(transaction, newStatus, result.PaymentType, nil, "")
+			return false, err
+		}
+	}
+
+	reason := fmt.Sprintf("expired: no payment within %s", ttl)
+
+	return s.applyStatus(transaction, TransactionStatusFailed, "", nil, reason)
+}
+
+// CancelTransaction membatalkan transaksi PENDING milik pembeli. Charge di
+// gateway dibatalkan lebih dulu supaya pembeli tidak bisa membayar lagi,
+// notifikasi berikutnya untuk order ini tidak mengubah apa pun
+func (s *transactionService) CancelTransaction(transactionID uuid.UUID, userID uuid.UUID) (*TransactionDTO, error) {
+	transaction, err := s.transactionRepository.GetTransactionsDetailByID(transactionID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// transaksi milik user lain diperlakukan seperti tidak ada
+	if transaction.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if transaction.Status != TransactionStatusPending {
+		return nil, ErrTransactionNotCancellable
+	}
+
+	result, err := s.gateway.Cancel(transaction.OrderID)
+	if err != nil && !errors.Is(err, payment.ErrOrderNotFound) {
+		// pembayaran mungkin sudah masuk, sinkronkan statusnya dulu
+		if status, statusErr := s.gateway.GetStatus(transaction.OrderID); statusErr == nil {
+			result = status
+		} else {
+			return nil, fmt.Errorf("%w: %v", ErrCancelFailed, err)
+		}
+	}
+
+	if result != nil {
+		switch newStatus := toTransactionStatus(result.Status); newStatus {
+		case TransactionStatusPaid, TransactionStatusChallenge:
+			if _, err := s.applyStatus(transaction, newStatus, result.PaymentType, nil, ""); err != nil {
+				return nil, err
+			}
+			return nil, ErrTransactionNotCancellable
+		case TransactionStatusPending:
+			return nil, fmt.Errorf("%w: charge is still pending at the gateway", ErrCancelFailed)
+		}
+	}
+
+	changed, err := s.applyStatus(transaction, TransactionStatusCancelled, "", nil, "cancelled by buyer")
+	if err != nil {
+		return nil, err
+	}
+
+	// status sudah dipindahkan notifikasi lain di antara pengecekan dan update
+	if !changed {
+		return nil, ErrTransactionNotCancellable
+	}
+
+	return &TransactionDTO{
+		ID:          transaction.ID,
+		OrderID:     transaction.OrderID,
+		Status:      string(TransactionStatusCancelled),
+		TotalAmount: transaction.TotalAmount,
+		PaymentType: transaction.PaymentType,
+		MerchantID:  transaction.MerchantID,
+		CreatedAt:   transaction.CreatedAt,
+	}, nil
+}
+
+// applyStatus memindahkan status transaksi yang masih terbuka. Reservasi
+// stok dikonversi saat transaksi benar-benar berpindah ke PAID dan dilepas
+// saat FAILED/CANCELLED, jadi notifikasi ganda tidak mengubah stok dua kali.
+// false berarti status sudah dipindahkan request lain
+func (s *transactionService) applyStatus(
+	transaction *Transaction,
+	newStatus TransactionStatus,
+	paymentType string,
+	reviewerID *uuid.UUID,
+	reason string,
+) (bool, error) {
+	var changed bool
+
+	err := s.db.Transaction(func(dbTx *gorm.DB) error {
 		trxRepo := s.transactionRepository.WithTx(dbTx)
 		stockRepo := s.stockMovementRepo.WithTx(dbTx)
+		reservationRepo := s.reservationRepo.WithTx(dbTx)
 
-		if err := trxRepo.UpdateStatusAndPaymentType(
-			req.OrderID,
-			newStatus,
-			req.PaymentType,
-		); err != nil {
+		from := transitionSources(newStatus)
+		if len(from) == 0 {
+			return nil
+		}
+
+		var err error
+		changed, err = trxRepo.TransitionStatus(transaction.ID, from, newStatus, paymentType)
+		if err != nil {
 			return err
 		}
 
-		if newStatus == TransactionStatusPaid {
+		if !changed {
+			return nil
+		}
+
+		if reviewerID != nil {
+			if err := trxRepo.MarkReviewed(transaction.ID, *reviewerID); err != nil {
+				return err
+			}
+		}
+
+		if reason != "" {
+			if err := trxRepo.SetStatusReason(transaction.ID, reason); err != nil {
+				return err
+			}
+		}
+
+		switch newStatus {
+		case TransactionStatusPaid:
+			converted, err := reservationRepo.Convert(transaction.ID)
+			if err != nil {
+				return err
+			}
+
+			// transaksi lama dibuat sebelum ada reservasi
+			if converted > 0 {
+				return nil
+			}
+
 			for _, item := range transaction.Items {
 				if err := stockRepo.AddStockSale(item.ProductID, item.Quantity); err != nil {
 					return err
 				}
 			}
+		case TransactionStatusFailed, TransactionStatusCancelled:
+			return reservationRepo.Release(transaction.ID)
 		}
 
 		return nil
 	})
+
+	return changed, err
+}
+
+func (s *transactionService) GetChallengedTransactions(merchantID *uuid.UUID) ([]TransactionDTO, error) {
+	return s.transactionRepository.GetChallengedTransactions(merchantID)
+}
+
+// ApproveChallenge menerima pembayaran yang di-challenge lewat payment gateway
+func (s *transactionService) ApproveChallenge(transactionID uuid.UUID, reviewerID uuid.UUID) (*TransactionDTO, error) {
+	return s.reviewChallenge(transactionID, reviewerID, true)
+}
+
+// DenyChallenge menolak pembayaran yang di-challenge lewat payment gateway
+func (s *transactionService) DenyChallenge(transactionID uuid.UUID, reviewerID uuid.UUID) (*TransactionDTO, error) {
+	return s.reviewChallenge(transactionID, reviewerID, false)
+}
+
+func (s *transactionService) reviewChallenge(transactionID uuid.UUID, reviewerID uuid.UUID, approve bool) (*TransactionDTO, error) {
+	transaction, err := s.transactionRepository.GetTransactionsDetailByID(transactionID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Status != TransactionStatusChallenge {
+		return nil, ErrTransactionNotChallenged
+	}
+
+	var result *payment.StatusResult
+
+	if approve {
+		result, err = s.gateway.Approve(transaction.OrderID)
+	} else {
+		result, err = s.gateway.Deny(transaction.OrderID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("payment review failed: %w", err)
+	}
+
+	newStatus := toTransactionStatus(result.Status)
+	if newStatus != TransactionStatusPaid && newStatus != TransactionStatusFailed {
+		return nil, fmt.Errorf("unexpected payment status after review: %s", result.ProviderStatus)
+	}
+
+	var reason string
+	if newStatus == TransactionStatusFailed {
+		reason = "payment denied by reviewer"
+	}
+
+	if _, err := s.applyStatus(transaction, newStatus, result.PaymentType, &reviewerID, reason); err != nil {
+		return nil, err
+	}
+
+	return &TransactionDTO{
+		ID:          transaction.ID,
+		OrderID:     transaction.OrderID,
+		Status:      string(newStatus),
+		TotalAmount: transaction.TotalAmount,
+		PaymentType: result.PaymentType,
+		MerchantID:  transaction.MerchantID,
+		CreatedAt:   transaction.CreatedAt,
+	}, nil
+}
+
+// rejectNotification mencatat notifikasi yang ditolak lalu mengembalikan
+// error yang membungkus ErrNotificationRejected
+func (s *transactionService) rejectNotification(
+	notification *payment.Notification,
+	payload []byte,
+	ipAddress string,
+	reason string,
+) error {
+	rejected := &RejectedPaymentNotification{
+		Reason:    reason,
+		Payload:   string(payload),
+		IPAddress: ipAddress,
+	}
+
+	// payload yang tidak bisa di-parse sama sekali tetap dicatat
+	if notification != nil {
+		rejected.OrderID = notification.OrderID
+		rejected.TransactionStatus = notification.ProviderStatus
+		rejected.StatusCode = notification.StatusCode
+		rejected.GrossAmount = notification.GrossAmount.String()
+	}
+
+	if err := s.notificationRepo.CreateRejected(rejected); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", ErrNotificationRejected, reason)
 }
 
 func (s *transactionService) GetTransactionDetail(id string) (*TransactionDetailResponse, error) {
@@ -296,12 +671,13 @@ func (s *transactionService) GetTransactionDetail(id string) (*TransactionDetail
 	items := make([]TransactionItemResponse, 0, len(tx.Items))
 	for _, item := range tx.Items {
 		items = append(items, TransactionItemResponse{
-			ID:          item.ID,
-			ProductID:   item.ProductID,
-			ProductName: item.Product.Name,
-			Quantity:    item.Quantity,
-			Price:       item.Price,
-			Subtotal:    item.Subtotal,
+			ID:               item.ID,
+			ProductID:        item.ProductID,
+			ProductName:      item.Product.Name,
+			Quantity:         item.Quantity,
+			Price:            item.Price,
+			Subtotal:         item.Subtotal,
+			RefundedQuantity: item.RefundedQuantity,
 		})
 	}
 
@@ -309,6 +685,7 @@ func (s *transactionService) GetTransactionDetail(id string) (*TransactionDetail
 		ID:             tx.ID,
 		OrderID:        tx.OrderID,
 		Status:         string(tx.Status),
+		StatusReason:   tx.StatusReason,
 		TotalAmount:    tx.TotalAmount,
 		PaymentType:    tx.PaymentType,
 		MerchantID:     tx.MerchantID,
@@ -316,6 +693,10 @@ func (s *transactionService) GetTransactionDetail(id string) (*TransactionDetail
 		IdempotencyKey: tx.IdempotencyKey,
 		CreatedAt:      tx.CreatedAt,
 		Items:          items,
+
+		FulfillmentStatus:    string(tx.CurrentFulfillment()),
+		FulfillmentNote:      tx.FulfillmentNote,
+		FulfillmentUpdatedAt: tx.FulfillmentUpdatedAt,
 	}
 
 	return resp, nil
@@ -333,15 +714,16 @@ func (s *transactionService) GetTransactionsByUserID(userID uuid.UUID) ([]Transa
 
 	for _, tx := range transactions {
 		resp = append(resp, TransactionDetailResponse{
-			ID:             tx.ID,
-			OrderID:        tx.OrderID,
-			Status:         string(tx.Status),
-			TotalAmount:    tx.TotalAmount,
-			MerchantName:   tx.MerchantName,
-			MerchantID:     tx.MerchantID,
-			PaymentType:    tx.PaymentType,
-			CreatedAt:      tx.CreatedAt,
-			IdempotencyKey: tx.IdempotencyKey,
+			ID:                tx.ID,
+			OrderID:           tx.OrderID,
+			Status:            string(tx.Status),
+			TotalAmount:       tx.TotalAmount,
+			MerchantName:      tx.MerchantName,
+			MerchantID:        tx.MerchantID,
+			PaymentType:       tx.PaymentType,
+			CreatedAt:         tx.CreatedAt,
+			IdempotencyKey:    tx.IdempotencyKey,
+			FulfillmentStatus: string(tx.CurrentFulfillment()),
 		})
 	}
 
@@ -349,7 +731,7 @@ func (s *transactionService) GetTransactionsByUserID(userID uuid.UUID) ([]Transa
 
 }
 
-func (s *transactionService) ResumeTransactionByIdempotencyKey(idempotencyKey string) (*CreateTransactionResponse, error) {
+func (s *transactionService) ResumeTransactionByIdempotencyKey(userID uuid.UUID, idempotencyKey string) (*CreateTransactionResponse, error) {
 	if idempotencyKey == "" {
 		return nil, fmt.Errorf("idempotency_key is required")
 	}
@@ -363,6 +745,11 @@ func (s *transactionService) ResumeTransactionByIdempotencyKey(idempotencyKey st
 		return nil, err
 	}
 
+	// transaksi milik user lain diperlakukan seperti tidak ada
+	if tx.UserID != userID {
+		return nil, fmt.Errorf("transaction not found")
+	}
+
 	if tx.SnapToken == "" || tx.RedirectURL == "" {
 		return nil, fmt.Errorf("transaction exists but missing payment info")
 	}
@@ -0,0 +1,365 @@
+package transactions
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"go-fiber-api/internal/features/auth"
+	"go-fiber-api/internal/features/inventory"
+	"go-fiber-api/internal/features/merchant"
+	"go-fiber-api/internal/features/products"
+	"go-fiber-api/internal/util/payment"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB butuh database postgres kosong di TEST_DATABASE_URL,
+// test dilewati kalau tidak diset
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect database: %v", err)
+	}
+
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+		t.Fatalf("create uuid extension: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&auth.User{},
+		&merchant.Merchant{},
+		&products.Product{},
+		&Transaction{},
+		&TransactionItem{},
+		&RejectedPaymentNotification{},
+		&Refund{},
+		&RefundItem{},
+		&inventory.StockMovement{},
+		&inventory.StockReservation{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return db
+}
+
+type paymentFixture struct {
+	db      *gorm.DB
+	gateway *payment.FakeGateway
+	service *transactionService
+	userID  uuid.UUID
+	product products.Product
+}
+
+func newPaymentFixture(t *testing.T, quantity int) *paymentFixture {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	user := auth.User{Email: uuid.NewString() + "@example.com", Password: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	m := merchant.Merchant{UserID: user.ID, Name: "Test Merchant"}
+	if err := db.Create(&m).Error; err != nil {
+		t.Fatalf("create merchant: %v", err)
+	}
+
+	product := products.Product{
+		MerchantID:      m.ID,
+		Name:            "Test Product",
+		Price:           decimal.NewFromInt(15000),
+		Quantity:        quantity,
+		ProductPhotoUrl: "http://localhost/photo.png",
+	}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	gateway := payment.NewFakeGateway("http://localhost")
+
+	service := NewTransactionService(
+		db,
+		NewTransactionRepository(db),
+		NewTransactionItemRepository(db),
+		products.NewProductRepository(db),
+		inventory.NewStockMovementRepository(db),
+		inventory.NewStockReservationRepository(db),
+		NewPaymentNotificationRepository(db),
+		gateway,
+	).(*transactionService)
+
+	return &paymentFixture{db: db, gateway: gateway, service: service, userID: user.ID, product: product}
+}
+
+func (f *paymentFixture) checkout(t *testing.T, quantity int) *Transaction {
+	t.Helper()
+
+	res, err := f.service.CreateTransaction(f.userID, &CreateTransactionRequest{
+		MerchantID:     f.product.MerchantID,
+		Items:          []CreateTransactionItemRequest{{ProductID: f.product.ID, Quantity: quantity}},
+		IdempotencyKey: uuid.NewString(),
+	})
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+
+	transaction, err := f.service.transactionRepository.FindByOrderID(res.OrderID)
+	if err != nil {
+		t.Fatalf("FindByOrderID: %v", err)
+	}
+
+	return transaction
+}
+
+func (f *paymentFixture) assertState(t *testing.T, transactionID uuid.UUID, status TransactionStatus, quantity int, reserved int) {
+	t.Helper()
+
+	transaction, err := f.service.transactionRepository.FindByID(transactionID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+
+	if transaction.Status != status {
+		t.Errorf("transaction status = %s, want %s", transaction.Status, status)
+	}
+
+	var product products.Product
+	if err := f.db.First(&product, "id = ?", f.product.ID).Error; err != nil {
+		t.Fatalf("find product: %v", err)
+	}
+
+	if product.Quantity != quantity || product.ReservedQuantity != reserved {
+		t.Errorf("product quantity/reserved = %d/%d, want %d/%d", product.Quantity, product.ReservedQuantity, quantity, reserved)
+	}
+}
+
+func (f *paymentFixture) reservationStatus(t *testing.T, transactionID uuid.UUID) inventory.StockReservationStatus {
+	t.Helper()
+
+	var reservation inventory.StockReservation
+	if err := f.db.First(&reservation, "transaction_id = ?", transactionID).Error; err != nil {
+		t.Fatalf("find reservation: %v", err)
+	}
+
+	return reservation.Status
+}
+
+func TestPaidNotificationConvertsReservation(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 2)
+
+	f.assertState(t, transaction.ID, TransactionStatusPending, 5, 2)
+
+	payload, err := f.gateway.Simulate(transaction.OrderID, payment.StatusPaid)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	// notifikasi yang dikirim ulang tidak boleh mengurangi stok dua kali
+	for i := 0; i < 2; i++ {
+		if err := f.service.HandlePaymentNotification(payload, "127.0.0.1"); err != nil {
+			t.Fatalf("HandlePaymentNotification #%d: %v", i+1, err)
+		}
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusPaid, 3, 0)
+
+	if status := f.reservationStatus(t, transaction.ID); status != inventory.ReservationConverted {
+		t.Errorf("reservation status = %s, want %s", status, inventory.ReservationConverted)
+	}
+}
+
+func TestDeniedPaymentReleasesReservation(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 2)
+
+	if err := f.service.SimulatePayment(transaction.ID, string(payment.StatusDenied), "127.0.0.1"); err != nil {
+		t.Fatalf("SimulatePayment: %v", err)
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusFailed, 5, 0)
+
+	if status := f.reservationStatus(t, transaction.ID); status != inventory.ReservationReleased {
+		t.Errorf("reservation status = %s, want %s", status, inventory.ReservationReleased)
+	}
+}
+
+func TestChallengeIsNotReopenedByPendingStatus(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 1)
+
+	if err := f.service.SimulatePayment(transaction.ID, string(payment.StatusChallenge), "127.0.0.1"); err != nil {
+		t.Fatalf("SimulatePayment: %v", err)
+	}
+
+	changed, err := f.service.applyStatus(transaction, TransactionStatusPending, "", nil, "")
+	if err != nil {
+		t.Fatalf("applyStatus: %v", err)
+	}
+
+	if changed {
+		t.Error("applyStatus(PENDING) changed a CHALLENGE transaction")
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusChallenge, 5, 1)
+
+	if err := f.service.SimulatePayment(transaction.ID, string(payment.StatusPaid), "127.0.0.1"); err != nil {
+		t.Fatalf("SimulatePayment: %v", err)
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusPaid, 4, 0)
+}
+
+func TestTamperedNotificationIsRejected(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 1)
+
+	payload, err := f.gateway.Simulate(transaction.OrderID, payment.StatusPaid)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	// notifikasi dari gateway lain ditandatangani dengan secret berbeda
+	other := newPaymentFixture(t, 5)
+	if err := other.service.HandlePaymentNotification(payload, "127.0.0.1"); !errors.Is(err, ErrNotificationRejected) {
+		t.Fatalf("HandlePaymentNotification error = %v, want %v", err, ErrNotificationRejected)
+	}
+
+	var rejected int64
+	if err := f.db.Model(&RejectedPaymentNotification{}).Where("order_id = ?", transaction.OrderID).Count(&rejected).Error; err != nil {
+		t.Fatalf("count rejected notifications: %v", err)
+	}
+
+	if rejected == 0 {
+		t.Error("rejected notification was not recorded")
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusPending, 5, 1)
+}
+
+// backdate memundurkan waktu checkout supaya transaksi dianggap kedaluwarsa
+func (f *paymentFixture) backdate(t *testing.T, transactionID uuid.UUID, age time.Duration) {
+	t.Helper()
+
+	if err := f.db.Model(&Transaction{}).Where("id = ?", transactionID).Update("created_at", time.Now().Add(-age)).Error; err != nil {
+		t.Fatalf("backdate transaction: %v", err)
+	}
+}
+
+func TestExpirePendingReleasesReservation(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	stale := f.checkout(t, 2)
+	fresh := f.checkout(t, 1)
+
+	f.backdate(t, stale.ID, 2*time.Hour)
+
+	expired, err := f.service.ExpirePendingTransactions(time.Hour, expiryBatchSize)
+	if err != nil {
+		t.Fatalf("ExpirePendingTransactions: %v", err)
+	}
+
+	if expired != 1 {
+		t.Fatalf("expired = %d, want 1", expired)
+	}
+
+	// hanya reservasi transaksi yang kedaluwarsa yang dilepas
+	f.assertState(t, stale.ID, TransactionStatusFailed, 5, 1)
+	f.assertState(t, fresh.ID, TransactionStatusPending, 5, 1)
+
+	if status := f.reservationStatus(t, stale.ID); status != inventory.ReservationReleased {
+		t.Errorf("reservation status = %s, want %s", status, inventory.ReservationReleased)
+	}
+
+	// sweep berikutnya tidak memproses transaksi yang sama lagi
+	if expired, err := f.service.ExpirePendingTransactions(time.Hour, expiryBatchSize); err != nil || expired != 0 {
+		t.Fatalf("second sweep = %d, %v, want 0", expired, err)
+	}
+}
+
+func TestExpireKeepsTransactionPaidAtGateway(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 2)
+
+	// pembayaran sudah masuk di gateway tapi notifikasinya belum sampai
+	if _, err := f.gateway.Simulate(transaction.OrderID, payment.StatusPaid); err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	f.backdate(t, transaction.ID, 2*time.Hour)
+
+	expired, err := f.service.ExpirePendingTransactions(time.Hour, expiryBatchSize)
+	if err != nil {
+		t.Fatalf("ExpirePendingTransactions: %v", err)
+	}
+
+	if expired != 0 {
+		t.Fatalf("expired = %d, want 0", expired)
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusPaid, 3, 0)
+}
+
+func TestCancelPendingTransaction(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 2)
+
+	// transaksi milik user lain diperlakukan seperti tidak ada
+	if _, err := f.service.CancelTransaction(transaction.ID, uuid.New()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("cancel by other user error = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+
+	dto, err := f.service.CancelTransaction(transaction.ID, f.userID)
+	if err != nil {
+		t.Fatalf("CancelTransaction: %v", err)
+	}
+
+	if dto.Status != string(TransactionStatusCancelled) {
+		t.Errorf("dto status = %s, want %s", dto.Status, TransactionStatusCancelled)
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusCancelled, 5, 0)
+
+	if status := f.reservationStatus(t, transaction.ID); status != inventory.ReservationReleased {
+		t.Errorf("reservation status = %s, want %s", status, inventory.ReservationReleased)
+	}
+
+	// charge di gateway ikut dibatalkan, pembeli tidak bisa membayar lagi
+	if _, err := f.gateway.Simulate(transaction.OrderID, payment.StatusPaid); !errors.Is(err, payment.ErrInvalidTransition) {
+		t.Fatalf("Simulate after cancel error = %v, want %v", err, payment.ErrInvalidTransition)
+	}
+
+	if _, err := f.service.CancelTransaction(transaction.ID, f.userID); !errors.Is(err, ErrTransactionNotCancellable) {
+		t.Fatalf("second cancel error = %v, want %v", err, ErrTransactionNotCancellable)
+	}
+}
+
+func TestCancelTransactionPaidAtGateway(t *testing.T) {
+	f := newPaymentFixture(t, 5)
+	transaction := f.checkout(t, 2)
+
+	// pembayaran masuk tepat sebelum pembeli menekan batal
+	if _, err := f.gateway.Simulate(transaction.OrderID, payment.StatusPaid); err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	if _, err := f.service.CancelTransaction(transaction.ID, f.userID); !errors.Is(err, ErrTransactionNotCancellable) {
+		t.Fatalf("CancelTransaction error = %v, want %v", err, ErrTransactionNotCancellable)
+	}
+
+	f.assertState(t, transaction.ID, TransactionStatusPaid, 3, 0)
+}
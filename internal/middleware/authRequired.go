@@ -3,28 +3,112 @@ package middleware
 import (
 	"go-fiber-api/internal/util/token"
 	"net/http"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
-func AuthRequired(c *fiber.Ctx) error {
-	tokenStr := c.Cookies("token")
+// SessionValidator dipakai untuk memastikan session milik token belum di-revoke
+// dan memang milik user di token
+type SessionValidator interface {
+	ValidateSession(sessionID uuid.UUID, userID uuid.UUID) error
+}
 
-	if tokenStr == "" {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"message": "unauthorized: missing auth token",
-		})
-	}
+// APIKeyPrincipal adalah identitas request yang memakai API key merchant
+type APIKeyPrincipal struct {
+	KeyID      uuid.UUID
+	MerchantID uuid.UUID
+	CreatedBy  uuid.UUID
+	Scopes     []string
+}
+
+type APIKeyValidator interface {
+	ValidateAPIKey(key string) (*APIKeyPrincipal, error)
+}
+
+const apiKeyLocal = "api_key"
+
+// AuthRequired menerima JWT dari cookie atau header Authorization: Bearer
+func AuthRequired(tokens *token.Tokens, sessions SessionValidator) fiber.Handler {
+	return authRequired(tokens, sessions, nil)
+}
+
+// AuthRequiredWithAPIKey sama dengan AuthRequired tapi juga menerima API key
+// merchant. Hanya dipasang di route yang memang boleh diakses integrasi
+// (POS, ERP), permission-nya dibatasi oleh scope key di resolver
+func AuthRequiredWithAPIKey(tokens *token.Tokens, sessions SessionValidator, apiKeys APIKeyValidator) fiber.Handler {
+	return authRequired(tokens, sessions, apiKeys)
+}
+
+// APIKeyFromContext mengembalikan principal kalau request memakai API key
+func APIKeyFromContext(c *fiber.Ctx) (*APIKeyPrincipal, bool) {
+	principal, ok := c.Locals(apiKeyLocal).(*APIKeyPrincipal)
+	return principal, ok && principal != nil
+}
 
-	claims, err := token.ParseToken(tokenStr)
+func authRequired(tokens *token.Tokens, sessions SessionValidator, apiKeys APIKeyValidator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenStr := bearerToken(c)
+		if tokenStr == "" {
+			tokenStr = tokens.Cookies.AccessTokenFromCookie(c)
+		}
 
-	if err != nil {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"message": "unauthorized: invalid auth token",
-		})
+		if tokenStr == "" {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "unauthorized: missing auth token",
+			})
+		}
+
+		if token.IsAPIKey(tokenStr) {
+			if apiKeys == nil {
+				return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+					"message": "unauthorized: api keys are not accepted on this endpoint",
+				})
+			}
+
+			principal, err := apiKeys.ValidateAPIKey(tokenStr)
+			if err != nil {
+				return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+					"message": "unauthorized: invalid api key",
+				})
+			}
+
+			// claims tanpa permission role, jadi API key hanya lolos
+			// pengecekan yang lewat resolver merchant
+			c.Locals("user_id", &token.CustomClaims{UserID: principal.CreatedBy})
+			c.Locals(apiKeyLocal, principal)
+
+			return c.Next()
+		}
+
+		claims, err := tokens.Keys.ParseToken(tokenStr)
+
+		if err != nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "unauthorized: invalid auth token",
+			})
+		}
+
+		if err := sessions.ValidateSession(claims.SessionID, claims.UserID); err != nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "unauthorized: session is no longer active",
+			})
+		}
+
+		c.Locals("user_id", claims)
+
+		return c.Next()
 	}
+}
+
+func bearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
 
-	c.Locals("user_id", claims)
+	scheme, value, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
 
-	return c.Next()
+	return strings.TrimSpace(value)
 }
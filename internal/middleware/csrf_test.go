@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-fiber-api/internal/config"
+	"go-fiber-api/internal/util/token"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newCSRFApp() *fiber.App {
+	cookies := token.NewCookiePolicy(config.CookieConfig{
+		AccessTokenName:  "token",
+		RefreshTokenName: "refresh_token",
+		CSRFTokenName:    "csrf_token",
+	})
+
+	ok := func(c *fiber.Ctx) error { return c.SendString(CSRFToken(c)) }
+
+	app := fiber.New()
+	app.Use(CSRFProtection(cookies, "/webhook"))
+	app.All("/*", ok)
+
+	return app
+}
+
+func TestCSRFProtection(t *testing.T) {
+	app := newCSRFApp()
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		cookies string
+		header  string
+		bearer  bool
+		status  int
+	}{
+		{name: "safe method", method: http.MethodGet, path: "/profile", cookies: "token=a", status: http.StatusOK},
+		{name: "no auth cookie", method: http.MethodPost, path: "/login", status: http.StatusOK},
+		{name: "matching header", method: http.MethodPost, path: "/profile", cookies: "token=a; csrf_token=secret", header: "secret", status: http.StatusOK},
+		{name: "missing header", method: http.MethodPost, path: "/profile", cookies: "token=a; csrf_token=secret", status: http.StatusForbidden},
+		{name: "wrong header", method: http.MethodDelete, path: "/profile", cookies: "token=a; csrf_token=secret", header: "other", status: http.StatusForbidden},
+		{name: "refresh cookie only", method: http.MethodPost, path: "/auth/refresh", cookies: "refresh_token=r; csrf_token=secret", status: http.StatusForbidden},
+		{name: "header without csrf cookie", method: http.MethodPost, path: "/profile", cookies: "token=a", header: "guess", status: http.StatusForbidden},
+		{name: "bearer token", method: http.MethodPost, path: "/profile", cookies: "token=a", bearer: true, status: http.StatusOK},
+		{name: "exempt path", method: http.MethodPost, path: "/webhook", cookies: "token=a", status: http.StatusOK},
+		{name: "exempt path prefix only", method: http.MethodPost, path: "/webhook/other", cookies: "token=a", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.cookies != "" {
+				req.Header.Set(fiber.HeaderCookie, tt.cookies)
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+			if tt.bearer {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer jwt")
+			}
+
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestCSRFProtectionIssuesToken(t *testing.T) {
+	app := newCSRFApp()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/csrf", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	var issued string
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "csrf_token" {
+			issued = cookie.Value
+		}
+	}
+
+	if issued == "" {
+		t.Fatal("csrf cookie was not set")
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	if string(body) != issued {
+		t.Fatalf("CSRFToken = %q, want cookie value %q", body, issued)
+	}
+
+	// cookie yang sudah ada dipakai ulang, tidak diganti
+	req := httptest.NewRequest(http.MethodGet, "/csrf", nil)
+	req.Header.Set(fiber.HeaderCookie, "csrf_token="+issued)
+
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "csrf_token" {
+			t.Fatalf("csrf cookie reissued as %q", cookie.Value)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"go-fiber-api/internal/common/response"
+	"go-fiber-api/internal/util/permission"
+	"go-fiber-api/internal/util/token"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidResource  = errors.New("invalid resource id")
+	ErrResourceNotFound = errors.New("resource not found")
+)
+
+// ResourceResolver mengembalikan permission yang dimiliki user terhadap
+// resource yang sedang diakses (misal merchant dari param :merchant_id)
+type ResourceResolver func(c *fiber.Ctx, userID uuid.UUID) ([]string, error)
+
+// RequirePermission harus dipasang setelah AuthRequired.
+// Tanpa resolver, permission dicek dari claims token (role user).
+// Dengan resolver, permission hanya dianggap valid kalau diberikan oleh
+// resolver, kecuali untuk admin platform yang memiliki permission.All.
+func RequirePermission(required string, resolvers ...ResourceResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user_id").(*token.CustomClaims)
+		if !ok || claims == nil {
+			return response.Fail(c, fiber.StatusUnauthorized, "unauthorized: invalid user claims")
+		}
+
+		if permission.Has(claims.Permissions, permission.All) {
+			return c.Next()
+		}
+
+		if len(resolvers) == 0 {
+			if permission.Has(claims.Permissions, required) {
+				return c.Next()
+			}
+			return response.Fail(c, fiber.StatusForbidden, "forbidden: missing permission "+required)
+		}
+
+		for _, resolve := range resolvers {
+			granted, err := resolve(c, claims.UserID)
+
+			switch {
+			case errors.Is(err, ErrInvalidResource):
+				return response.Fail(c, fiber.StatusBadRequest, err.Error())
+			case errors.Is(err, ErrResourceNotFound):
+				return response.Fail(c, fiber.StatusNotFound, err.Error())
+			case err != nil:
+				log.Println("permission resolver error:", err)
+				return response.Fail(c, fiber.StatusInternalServerError, "failed to check permission")
+			}
+
+			if permission.Has(granted, required) {
+				return c.Next()
+			}
+		}
+
+		return response.Fail(c, fiber.StatusForbidden, "forbidden: missing permission "+required)
+	}
+}
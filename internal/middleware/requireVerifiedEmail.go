@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"go-fiber-api/internal/util/token"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type EmailVerificationChecker interface {
+	IsEmailVerified(userID uuid.UUID) (bool, error)
+}
+
+// RequireVerifiedEmail harus dipasang setelah AuthRequired. Kalau enabled false
+// middleware ini langsung lanjut ke handler berikutnya
+func RequireVerifiedEmail(checker EmailVerificationChecker, enabled bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !enabled {
+			return c.Next()
+		}
+
+		claims, ok := c.Locals("user_id").(*token.CustomClaims)
+		if !ok || claims == nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "unauthorized: invalid user claims",
+			})
+		}
+
+		verified, err := checker.IsEmailVerified(claims.UserID)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed to check email verification",
+			})
+		}
+
+		if !verified {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"message": "forbidden: email address is not verified",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer tidak benar-benar mengirim email. Kalau dir diisi, setiap email
+// ditulis sebagai file .eml (berguna untuk test), kalau kosong hanya
+// penerima dan subjek yang di-log. Isi email tidak pernah masuk log karena
+// berisi link reset password dan verifikasi yang masih berlaku.
+type LogMailer struct {
+	dir  string
+	from string
+}
+
+func NewLogMailer(dir, from string) *LogMailer {
+	return &LogMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if m.dir == "" {
+		log.Printf("mail to=%s subject=%q (body not logged, set MAIL_OUTPUT_DIR to keep it)", msg.To, msg.Subject)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("create mail dir failed: %w", err)
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To)
+	fileName := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), recipient)
+
+	return os.WriteFile(filepath.Join(m.dir, fileName), buildMessage(m.from, msg), 0o644)
+}
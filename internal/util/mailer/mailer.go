@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"go-fiber-api/internal/config"
+	"log"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New memilih implementasi mailer berdasarkan MAIL_DRIVER. Driver "log"
+// hanya menjadi default di APP_ENV=development supaya tidak butuh SMTP server
+func New(cfg *config.Config) Mailer {
+	switch cfg.MailDriver {
+	case "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	default:
+		return NewLogMailer(cfg.MailOutputDir, cfg.MailFrom)
+	}
+}
+
+// SendAsync mengirim email di goroutine terpisah supaya request tidak
+// menunggu mail server (dan waktu respon tidak membocorkan apa pun)
+func SendAsync(m Mailer, msg Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := m.Send(ctx, msg); err != nil {
+			log.Println("failed to send email:", err)
+		}
+	}()
+}
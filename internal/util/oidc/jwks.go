@@ -0,0 +1,170 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// JWKS di-fetch ulang paling sering sekali per interval ini
+// saat ada kid yang belum dikenal (provider sedang rotasi key)
+const jwksRefreshInterval = time.Minute
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type keySet struct {
+	uri     string
+	getJSON func(ctx context.Context, target string, out interface{}) error
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	lastFetched time.Time
+}
+
+func newKeySet(uri string, getJSON func(ctx context.Context, target string, out interface{}) error) *keySet {
+	return &keySet{
+		uri:     uri,
+		getJSON: getJSON,
+		keys:    map[string]interface{}{},
+	}
+}
+
+func (k *keySet) get(ctx context.Context, kid string) (interface{}, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key, ok := k.lookup(kid); ok {
+		return key, nil
+	}
+
+	if time.Since(k.lastFetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if err := k.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	if key, ok := k.lookup(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookup tanpa kid hanya boleh kalau JWKS berisi tepat satu key
+func (k *keySet) lookup(kid string) (interface{}, bool) {
+	if kid != "" {
+		key, ok := k.keys[kid]
+		return key, ok
+	}
+
+	if len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+func (k *keySet) refresh(ctx context.Context) error {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	k.lastFetched = time.Now()
+
+	if err := k.getJSON(ctx, k.uri, &doc); err != nil {
+		return fmt.Errorf("fetch jwks failed: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return errors.New("jwks contains no usable signing keys")
+	}
+
+	k.keys = keys
+	return nil
+}
+
+func (j jsonWebKey) publicKey() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,153 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"go-fiber-api/internal/util/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestProvider(t *testing.T) (*oidctest.Provider, *Provider) {
+	t.Helper()
+
+	mock := oidctest.NewProvider(t, "client-id", "client-secret")
+
+	return mock, NewProvider(Config{
+		Issuer:       mock.Issuer(),
+		ClientID:     mock.ClientID,
+		ClientSecret: mock.ClientSecret,
+		RedirectURL:  "http://localhost/api/auth/oidc/mock/callback",
+	})
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// base64url tanpa padding dari SHA-256 verifier
+	got := CodeChallenge("verifier-0")
+	if want := "EqA-Truq2E3T7ZHntu_RcI4c0mwlJC-se84e1IKK-ZU"; got != want {
+		t.Fatalf("CodeChallenge = %s, want %s", got, want)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	_, provider := newTestProvider(t)
+
+	raw, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", CodeChallenge("verifier"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-id",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        CodeChallenge("verifier"),
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	}
+
+	for key, value := range want {
+		if got := u.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestExchangeAndVerify(t *testing.T) {
+	mock, provider := newTestProvider(t)
+	ctx := context.Background()
+
+	verifier, err := RandomString()
+	if err != nil {
+		t.Fatalf("RandomString: %v", err)
+	}
+
+	code := mock.IssueCode(CodeChallenge(verifier), mock.Claims("subject-1", "user@example.com", "nonce-1"))
+
+	// code verifier yang salah harus ditolak token endpoint
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Fatal("Exchange with wrong verifier succeeded, want error")
+	}
+
+	code = mock.IssueCode(CodeChallenge(verifier), mock.Claims("subject-1", "user@example.com", "nonce-1"))
+
+	tokens, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+
+	if claims.Subject != "subject-1" || claims.Email != "user@example.com" || !claims.EmailVerified {
+		t.Fatalf("claims = %+v, want subject-1 / user@example.com / verified", claims)
+	}
+
+	// code hanya bisa ditukar sekali
+	if _, err := provider.Exchange(ctx, code, verifier); err == nil {
+		t.Fatal("Exchange with used code succeeded, want error")
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	mock, provider := newTestProvider(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	withClaim := func(key string, value interface{}) string {
+		claims := mock.Claims("subject-1", "user@example.com", "nonce-1")
+		claims[key] = value
+		return mock.SignIDToken(claims)
+	}
+
+	signedWith := func(method jwt.SigningMethod, key interface{}) string {
+		t := jwt.NewWithClaims(method, mock.Claims("subject-1", "user@example.com", "nonce-1"))
+		t.Header["kid"] = "oidctest-key"
+
+		signed, err := t.SignedString(key)
+		if err != nil {
+			panic(err)
+		}
+		return signed
+	}
+
+	tests := map[string]struct {
+		token string
+		nonce string
+	}{
+		"nonce mismatch":    {token: mock.SignIDToken(mock.Claims("subject-1", "user@example.com", "nonce-1")), nonce: "nonce-2"},
+		"wrong audience":    {token: withClaim("aud", "another-client"), nonce: "nonce-1"},
+		"wrong issuer":      {token: withClaim("iss", "https://evil.example.com"), nonce: "nonce-1"},
+		"expired":           {token: withClaim("exp", time.Now().Add(-time.Hour).Unix()), nonce: "nonce-1"},
+		"missing subject":   {token: withClaim("sub", ""), nonce: "nonce-1"},
+		"foreign signature": {token: signedWith(jwt.SigningMethodRS256, otherKey), nonce: "nonce-1"},
+		"hmac algorithm":    {token: signedWith(jwt.SigningMethodHS256, []byte("client-secret")), nonce: "nonce-1"},
+		"malformed":         {token: "not-a-jwt", nonce: "nonce-1"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := provider.VerifyIDToken(context.Background(), tt.token, tt.nonce)
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("VerifyIDToken error = %v, want %v", err, ErrInvalidIDToken)
+			}
+		})
+	}
+}
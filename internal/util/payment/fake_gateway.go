@@ -0,0 +1,241 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FakeGateway menyimpan pembayaran di memori untuk test dan development
+// lokal. Status diubah lewat Simulate, yang menghasilkan payload notifikasi
+// bertanda tangan seperti webhook dari provider sungguhan
+type FakeGateway struct {
+	mu       sync.Mutex
+	baseURL  string
+	secret   []byte
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	status      Status
+	paymentType string
+	grossAmount decimal.Decimal
+	refunded    decimal.Decimal
+}
+
+type fakeNotification struct {
+	OrderID     string `json:"order_id"`
+	Status      Status `json:"status"`
+	PaymentType string `json:"payment_type"`
+	GrossAmount string `json:"gross_amount"`
+	Signature   string `json:"signature"`
+}
+
+func NewFakeGateway(baseURL string) *FakeGateway {
+	// secret hanya hidup selama proses berjalan, notifikasi palsu tidak
+	// pernah datang dari luar
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("payment: failed to generate fake gateway secret: %v", err))
+	}
+
+	return &FakeGateway{
+		baseURL:  baseURL,
+		secret:   secret,
+		payments: map[string]*fakePayment{},
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateCharge(req ChargeRequest) (*Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.payments[req.OrderID]; exists {
+		return nil, fmt.Errorf("fake charge failed: order %s already exists", req.OrderID)
+	}
+
+	g.payments[req.OrderID] = &fakePayment{
+		status:      StatusPending,
+		grossAmount: req.GrossAmount.Round(0),
+	}
+
+	return &Charge{
+		Token:       "fake-" + uuid.NewString(),
+		RedirectURL: fmt.Sprintf("%s/fake-payment/%s", g.baseURL, req.OrderID),
+	}, nil
+}
+
+func (g *FakeGateway) GetStatus(orderID string) (*StatusResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.payments[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	return p.result(orderID), nil
+}
+
+func (g *FakeGateway) Cancel(orderID string) (*StatusResult, error) {
+	return g.transition(orderID, StatusCancelled, StatusPending, StatusChallenge)
+}
+
+func (g *FakeGateway) Approve(orderID string) (*StatusResult, error) {
+	return g.transition(orderID, StatusPaid, StatusChallenge)
+}
+
+func (g *FakeGateway) Deny(orderID string) (*StatusResult, error) {
+	return g.transition(orderID, StatusDenied, StatusChallenge)
+}
+
+func (g *FakeGateway) Refund(orderID string, req RefundRequest) (*RefundResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.payments[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	if p.status != StatusPaid && p.status != StatusPartiallyRefunded {
+		return nil, fmt.Errorf("%w: cannot refund %s payment", ErrInvalidTransition, p.status)
+	}
+
+	remaining := p.grossAmount.Sub(p.refunded)
+	if req.Amount.LessThanOrEqual(decimal.Zero) || req.Amount.GreaterThan(remaining) {
+		return nil, fmt.Errorf("fake refund failed: amount must be between 1 and %s", remaining)
+	}
+
+	p.refunded = p.refunded.Add(req.Amount)
+	p.status = StatusPartiallyRefunded
+	if p.refunded.Equal(p.grossAmount) {
+		p.status = StatusRefunded
+	}
+
+	return &RefundResult{
+		RefundKey: req.RefundKey,
+		Amount:    req.Amount,
+		Status:    p.status,
+	}, nil
+}
+
+// Simulate mengubah status pembayaran seolah-olah dari provider lalu
+// mengembalikan payload notifikasi untuk diproses seperti webhook biasa.
+// Yang didukung: paid (settlement), challenge, expired dan denied
+func (g *FakeGateway) Simulate(orderID string, status Status) ([]byte, error) {
+	var from []Status
+
+	switch status {
+	case StatusPaid, StatusDenied:
+		from = []Status{StatusPending, StatusChallenge}
+	case StatusChallenge, StatusExpired:
+		from = []Status{StatusPending}
+	default:
+		return nil, fmt.Errorf("%w: cannot simulate %q", ErrInvalidTransition, status)
+	}
+
+	result, err := g.transition(orderID, status, from...)
+	if err != nil {
+		return nil, err
+	}
+
+	notification := fakeNotification{
+		OrderID:     result.OrderID,
+		Status:      result.Status,
+		PaymentType: result.PaymentType,
+		GrossAmount: result.GrossAmount.String(),
+	}
+	notification.Signature = g.sign(notification)
+
+	return json.Marshal(notification)
+}
+
+func (g *FakeGateway) ParseNotification(payload []byte) (*Notification, error) {
+	var req fakeNotification
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidNotification)
+	}
+
+	amount, _ := decimal.NewFromString(req.GrossAmount)
+
+	notification := &Notification{
+		StatusResult: StatusResult{
+			OrderID:        req.OrderID,
+			Status:         req.Status,
+			PaymentType:    req.PaymentType,
+			GrossAmount:    amount,
+			ProviderStatus: string(req.Status),
+		},
+	}
+
+	if !hmac.Equal([]byte(g.sign(req)), []byte(req.Signature)) {
+		return notification, fmt.Errorf("%w: invalid signature", ErrInvalidNotification)
+	}
+
+	// sama seperti verifikasi status API Midtrans, status diambil dari state
+	status, err := g.GetStatus(req.OrderID)
+	if err != nil {
+		return notification, fmt.Errorf("%w: unknown order_id", ErrInvalidNotification)
+	}
+
+	notification.StatusResult = *status
+
+	return notification, nil
+}
+
+func (g *FakeGateway) transition(orderID string, to Status, from ...Status) (*StatusResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.payments[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	allowed := false
+	for _, status := range from {
+		if p.status == status {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, p.status, to)
+	}
+
+	p.status = to
+	if to == StatusPaid || to == StatusChallenge {
+		p.paymentType = "fake"
+	}
+
+	return p.result(orderID), nil
+}
+
+func (g *FakeGateway) sign(n fakeNotification) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(n.OrderID + string(n.Status) + n.PaymentType + n.GrossAmount))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *fakePayment) result(orderID string) *StatusResult {
+	return &StatusResult{
+		OrderID:        orderID,
+		Status:         p.status,
+		PaymentType:    p.paymentType,
+		GrossAmount:    p.grossAmount,
+		ProviderStatus: string(p.status),
+	}
+}
@@ -0,0 +1,190 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func newFakeCharge(t *testing.T, g *FakeGateway, orderID string, amount int64) {
+	t.Helper()
+
+	if _, err := g.CreateCharge(ChargeRequest{OrderID: orderID, GrossAmount: decimal.NewFromInt(amount)}); err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+}
+
+func TestFakeGatewaySimulateTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    []Status
+		to      Status
+		wantErr error
+	}{
+		{name: "pending to paid", to: StatusPaid},
+		{name: "pending to denied", to: StatusDenied},
+		{name: "pending to challenge", to: StatusChallenge},
+		{name: "pending to expired", to: StatusExpired},
+		{name: "challenge to paid", path: []Status{StatusChallenge}, to: StatusPaid},
+		{name: "challenge to denied", path: []Status{StatusChallenge}, to: StatusDenied},
+		{name: "challenge to expired", path: []Status{StatusChallenge}, to: StatusExpired, wantErr: ErrInvalidTransition},
+		{name: "paid to denied", path: []Status{StatusPaid}, to: StatusDenied, wantErr: ErrInvalidTransition},
+		{name: "expired to paid", path: []Status{StatusExpired}, to: StatusPaid, wantErr: ErrInvalidTransition},
+		{name: "unsupported status", to: StatusRefunded, wantErr: ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewFakeGateway("http://localhost")
+			newFakeCharge(t, g, "order-1", 10000)
+
+			for _, status := range tt.path {
+				if _, err := g.Simulate("order-1", status); err != nil {
+					t.Fatalf("Simulate(%s): %v", status, err)
+				}
+			}
+
+			_, err := g.Simulate("order-1", tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Simulate(%s) error = %v, want %v", tt.to, err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Simulate(%s): %v", tt.to, err)
+			}
+
+			result, err := g.GetStatus("order-1")
+			if err != nil {
+				t.Fatalf("GetStatus: %v", err)
+			}
+
+			if result.Status != tt.to {
+				t.Fatalf("status = %s, want %s", result.Status, tt.to)
+			}
+		})
+	}
+}
+
+func TestFakeGatewayUnknownOrder(t *testing.T) {
+	g := NewFakeGateway("http://localhost")
+
+	if _, err := g.Simulate("missing", StatusPaid); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("Simulate error = %v, want %v", err, ErrOrderNotFound)
+	}
+
+	if _, err := g.GetStatus("missing"); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("GetStatus error = %v, want %v", err, ErrOrderNotFound)
+	}
+}
+
+func TestFakeGatewayParseNotification(t *testing.T) {
+	g := NewFakeGateway("http://localhost")
+	newFakeCharge(t, g, "order-1", 10000)
+
+	payload, err := g.Simulate("order-1", StatusPaid)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	notification, err := g.ParseNotification(payload)
+	if err != nil {
+		t.Fatalf("ParseNotification: %v", err)
+	}
+
+	if notification.OrderID != "order-1" || notification.Status != StatusPaid {
+		t.Fatalf("notification = %s/%s, want order-1/%s", notification.OrderID, notification.Status, StatusPaid)
+	}
+
+	if !notification.GrossAmount.Equal(decimal.NewFromInt(10000)) {
+		t.Fatalf("gross amount = %s, want 10000", notification.GrossAmount)
+	}
+}
+
+func TestFakeGatewayRejectsInvalidNotification(t *testing.T) {
+	g := NewFakeGateway("http://localhost")
+	newFakeCharge(t, g, "order-1", 10000)
+
+	payload, err := g.Simulate("order-1", StatusDenied)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	var tampered fakeNotification
+	if err := json.Unmarshal(payload, &tampered); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	tampered.Status = StatusPaid
+
+	tamperedPayload, err := json.Marshal(tampered)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	// gateway lain punya secret berbeda, tanda tangannya tidak boleh diterima
+	other := NewFakeGateway("http://localhost")
+	newFakeCharge(t, other, "order-1", 10000)
+	otherPayload, err := other.Simulate("order-1", StatusPaid)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	for name, p := range map[string][]byte{
+		"tampered status": tamperedPayload,
+		"foreign secret":  otherPayload,
+		"malformed":       []byte("{"),
+	} {
+		if _, err := g.ParseNotification(p); !errors.Is(err, ErrInvalidNotification) {
+			t.Errorf("%s: ParseNotification error = %v, want %v", name, err, ErrInvalidNotification)
+		}
+	}
+}
+
+func TestFakeGatewayRefundBounds(t *testing.T) {
+	g := NewFakeGateway("http://localhost")
+	newFakeCharge(t, g, "order-1", 10000)
+
+	if _, err := g.Refund("order-1", RefundRequest{Amount: decimal.NewFromInt(1000)}); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("refund before paid error = %v, want %v", err, ErrInvalidTransition)
+	}
+
+	if _, err := g.Simulate("order-1", StatusPaid); err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+
+	for _, amount := range []int64{0, -1, 10001} {
+		if _, err := g.Refund("order-1", RefundRequest{Amount: decimal.NewFromInt(amount)}); err == nil {
+			t.Fatalf("refund %d succeeded, want error", amount)
+		}
+	}
+
+	partial, err := g.Refund("order-1", RefundRequest{RefundKey: "r-1", Amount: decimal.NewFromInt(4000)})
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+
+	if partial.Status != StatusPartiallyRefunded {
+		t.Fatalf("partial refund status = %s, want %s", partial.Status, StatusPartiallyRefunded)
+	}
+
+	if _, err := g.Refund("order-1", RefundRequest{Amount: decimal.NewFromInt(6001)}); err == nil {
+		t.Fatal("refund above remaining amount succeeded, want error")
+	}
+
+	full, err := g.Refund("order-1", RefundRequest{RefundKey: "r-2", Amount: decimal.NewFromInt(6000)})
+	if err != nil {
+		t.Fatalf("remaining refund: %v", err)
+	}
+
+	if full.Status != StatusRefunded {
+		t.Fatalf("full refund status = %s, want %s", full.Status, StatusRefunded)
+	}
+
+	if _, err := g.Refund("order-1", RefundRequest{Amount: decimal.NewFromInt(1)}); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("refund after fully refunded error = %v, want %v", err, ErrInvalidTransition)
+	}
+}
@@ -0,0 +1,294 @@
+package payment
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/config"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/midtrans/midtrans-go/snap"
+	"github.com/shopspring/decimal"
+)
+
+type MidtransGateway struct {
+	snapClient snap.Client
+	coreClient coreapi.Client
+	config     config.MidtransConfig
+}
+
+// midtransNotification mewakili payload penting dari webhook Midtrans
+type midtransNotification struct {
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	PaymentType       string `json:"payment_type"`
+	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+}
+
+func NewMidtransGateway(cfg config.MidtransConfig) *MidtransGateway {
+	var snapClient snap.Client
+	snapClient.New(cfg.ServerKey, cfg.EnvironmentType())
+
+	// notifikasi dikirim ke URL ini, bukan yang diatur di dashboard Midtrans
+	if cfg.NotificationURL != "" {
+		snapClient.Options.SetPaymentOverrideNotification(cfg.NotificationURL)
+	}
+
+	var coreClient coreapi.Client
+	coreClient.New(cfg.ServerKey, cfg.EnvironmentType())
+
+	return &MidtransGateway{
+		snapClient: snapClient,
+		coreClient: coreClient,
+		config:     cfg,
+	}
+}
+
+func (g *MidtransGateway) Name() string {
+	return "midtrans"
+}
+
+func (g *MidtransGateway) CreateCharge(req ChargeRequest) (*Charge, error) {
+	snapResp, mErr := g.snapClient.CreateTransaction(g.newSnapRequest(req))
+
+	// Library Midtrans kadang mengembalikan error namun HTTP 200 dan body
+	// berisi token. Kalau token ada, anggap sukses dan abaikan error-nya
+	if snapResp != nil && snapResp.Token != "" {
+		return &Charge{
+			Token:       snapResp.Token,
+			RedirectURL: snapResp.RedirectURL,
+		}, nil
+	}
+
+	if mErr != nil {
+		return nil, fmt.Errorf("failed to create snap transaction: %s", mErr.GetMessage())
+	}
+
+	return nil, fmt.Errorf("failed to create snap transaction: empty response from Midtrans")
+}
+
+// newSnapRequest melengkapi request Snap dengan pengaturan dari MidtransConfig
+func (g *MidtransGateway) newSnapRequest(req ChargeRequest) *snap.Request {
+	itemDetails := make([]midtrans.ItemDetails, 0, len(req.Items))
+	for _, item := range req.Items {
+		itemDetails = append(itemDetails, midtrans.ItemDetails{
+			ID:   item.ID,
+			Name: item.Name,
+			// Midtrans tetap butuh integer rupiah, pakai nilai dibulatkan
+			Price: item.Price.Round(0).IntPart(),
+			Qty:   int32(item.Quantity),
+		})
+	}
+
+	snapReq := &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.OrderID,
+			GrossAmt: req.GrossAmount.Round(0).IntPart(),
+		},
+		Items:  &itemDetails,
+		UserId: req.CustomerID,
+	}
+
+	for _, payment := range g.config.EnabledPayments {
+		snapReq.EnabledPayments = append(snapReq.EnabledPayments, snap.SnapPaymentType(payment))
+	}
+
+	if g.config.Expiry > 0 {
+		snapReq.Expiry = &snap.ExpiryDetails{
+			Unit:     "minute",
+			Duration: int64(g.config.Expiry / time.Minute),
+		}
+	}
+
+	if g.config.FinishURL != "" {
+		snapReq.Callbacks = &snap.Callbacks{Finish: g.config.FinishURL}
+	}
+
+	customFields := []*string{&snapReq.CustomField1, &snapReq.CustomField2, &snapReq.CustomField3}
+	for i, value := range g.config.CustomFields {
+		if i < len(customFields) {
+			*customFields[i] = value
+		}
+	}
+
+	return snapReq
+}
+
+func (g *MidtransGateway) GetStatus(orderID string) (*StatusResult, error) {
+	status, mErr := g.coreClient.CheckTransaction(orderID)
+	if mErr != nil {
+		return nil, midtransError("check status", orderID, mErr)
+	}
+
+	return newMidtransStatusResult(orderID, status.TransactionStatus, status.FraudStatus, status.PaymentType, status.GrossAmount), nil
+}
+
+func (g *MidtransGateway) Cancel(orderID string) (*StatusResult, error) {
+	result, mErr := g.coreClient.CancelTransaction(orderID)
+	if mErr != nil {
+		return nil, midtransError("cancel", orderID, mErr)
+	}
+
+	return newMidtransStatusResult(orderID, result.TransactionStatus, result.FraudStatus, result.PaymentType, result.GrossAmount), nil
+}
+
+func (g *MidtransGateway) Approve(orderID string) (*StatusResult, error) {
+	result, mErr := g.coreClient.ApproveTransaction(orderID)
+	if mErr != nil {
+		return nil, midtransError("approve", orderID, mErr)
+	}
+
+	return newMidtransStatusResult(orderID, result.TransactionStatus, result.FraudStatus, result.PaymentType, result.GrossAmount), nil
+}
+
+func (g *MidtransGateway) Deny(orderID string) (*StatusResult, error) {
+	result, mErr := g.coreClient.DenyTransaction(orderID)
+	if mErr != nil {
+		return nil, midtransError("deny", orderID, mErr)
+	}
+
+	return newMidtransStatusResult(orderID, result.TransactionStatus, result.FraudStatus, result.PaymentType, result.GrossAmount), nil
+}
+
+func (g *MidtransGateway) Refund(orderID string, req RefundRequest) (*RefundResult, error) {
+	result, mErr := g.coreClient.RefundTransaction(orderID, &coreapi.RefundReq{
+		RefundKey: req.RefundKey,
+		Amount:    req.Amount.Round(0).IntPart(),
+		Reason:    req.Reason,
+	})
+	if mErr != nil {
+		return nil, midtransError("refund", orderID, mErr)
+	}
+
+	amount, err := decimal.NewFromString(result.RefundAmount)
+	if err != nil {
+		amount = req.Amount
+	}
+
+	return &RefundResult{
+		RefundKey: req.RefundKey,
+		Amount:    amount,
+		Status:    mapMidtransStatus(result.TransactionStatus, result.FraudStatus),
+	}, nil
+}
+
+func (g *MidtransGateway) ParseNotification(payload []byte) (*Notification, error) {
+	var req midtransNotification
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidNotification)
+	}
+
+	notification := &Notification{
+		StatusResult: *newMidtransStatusResult(req.OrderID, req.TransactionStatus, req.FraudStatus, req.PaymentType, req.GrossAmount),
+		StatusCode:   req.StatusCode,
+	}
+
+	if req.OrderID == "" {
+		return notification, fmt.Errorf("%w: order_id is required", ErrInvalidNotification)
+	}
+
+	if !g.validSignature(&req) {
+		return notification, fmt.Errorf("%w: invalid signature_key", ErrInvalidNotification)
+	}
+
+	if !g.config.VerifyStatus {
+		return notification, nil
+	}
+
+	status, err := g.GetStatus(req.OrderID)
+	if err != nil {
+		if errors.Is(err, ErrOrderNotFound) {
+			return notification, fmt.Errorf("%w: order_id not found in Midtrans", ErrInvalidNotification)
+		}
+		return notification, err
+	}
+
+	if !status.GrossAmount.Equal(notification.GrossAmount) {
+		return notification, fmt.Errorf("%w: status API gross_amount does not match notification", ErrInvalidNotification)
+	}
+
+	// isi body tidak dipercaya, pakai status dari API
+	notification.StatusResult = *status
+
+	return notification, nil
+}
+
+// validSignature mengecek signature_key = SHA512(order_id+status_code+gross_amount+server_key)
+func (g *MidtransGateway) validSignature(req *midtransNotification) bool {
+	sum := sha512.Sum512([]byte(req.OrderID + req.StatusCode + req.GrossAmount + g.config.ServerKey))
+	expected := hex.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(req.SignatureKey))) == 1
+}
+
+func newMidtransStatusResult(orderID, transactionStatus, fraudStatus, paymentType, grossAmount string) *StatusResult {
+	// gross_amount yang tidak bisa dibaca jadi nol dan pasti tidak cocok
+	// dengan total transaksi
+	amount, _ := decimal.NewFromString(grossAmount)
+
+	providerStatus := transactionStatus
+	if fraudStatus != "" {
+		providerStatus += "/" + fraudStatus
+	}
+
+	return &StatusResult{
+		OrderID:        orderID,
+		Status:         mapMidtransStatus(transactionStatus, fraudStatus),
+		PaymentType:    paymentType,
+		GrossAmount:    amount,
+		ProviderStatus: providerStatus,
+	}
+}
+
+// mapMidtransStatus: capture kartu kredit baru dianggap lunas kalau
+// fraud_status accept, challenge masuk antrian review
+func mapMidtransStatus(transactionStatus string, fraudStatus string) Status {
+	switch transactionStatus {
+	case "capture":
+		switch fraudStatus {
+		case "challenge":
+			return StatusChallenge
+		case "deny":
+			return StatusDenied
+		default:
+			return StatusPaid
+		}
+	case "settlement":
+		if fraudStatus == "deny" {
+			return StatusDenied
+		}
+		return StatusPaid
+	case "deny":
+		return StatusDenied
+	case "cancel":
+		return StatusCancelled
+	case "expire":
+		return StatusExpired
+	case "refund":
+		return StatusRefunded
+	case "partial_refund":
+		return StatusPartiallyRefunded
+	default:
+		return StatusPending
+	}
+}
+
+// midtransError: 404 artinya Midtrans tidak mengenal order tersebut
+func midtransError(action string, orderID string, mErr *midtrans.Error) error {
+	if mErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	return fmt.Errorf("midtrans %s failed: %s", action, mErr.GetMessage())
+}
@@ -0,0 +1,108 @@
+package payment
+
+import (
+	"errors"
+
+	"go-fiber-api/internal/config"
+
+	"github.com/shopspring/decimal"
+)
+
+// Status adalah status pembayaran yang sudah dinormalisasi, jadi service
+// tidak perlu tahu istilah masing-masing provider
+type Status string
+
+const (
+	StatusPending           Status = "pending"
+	StatusChallenge         Status = "challenge"
+	StatusPaid              Status = "paid"
+	StatusDenied            Status = "denied"
+	StatusExpired           Status = "expired"
+	StatusCancelled         Status = "cancelled"
+	StatusRefunded          Status = "refunded"
+	StatusPartiallyRefunded Status = "partially_refunded"
+)
+
+var (
+	// ErrInvalidNotification dipakai untuk notifikasi yang tidak boleh
+	// diproses (signature salah, order tidak dikenal provider, dsb)
+	ErrInvalidNotification = errors.New("invalid payment notification")
+	ErrOrderNotFound       = errors.New("order not found in payment gateway")
+	ErrInvalidTransition   = errors.New("payment cannot move to the requested status")
+)
+
+type Item struct {
+	ID       string
+	Name     string
+	Price    decimal.Decimal
+	Quantity int
+}
+
+type ChargeRequest struct {
+	OrderID     string
+	GrossAmount decimal.Decimal
+	Items       []Item
+	CustomerID  string
+}
+
+type Charge struct {
+	Token       string
+	RedirectURL string
+}
+
+type StatusResult struct {
+	OrderID     string
+	Status      Status
+	PaymentType string
+	GrossAmount decimal.Decimal
+	// ProviderStatus adalah status asli dari provider, untuk log dan audit
+	ProviderStatus string
+}
+
+type Notification struct {
+	StatusResult
+	StatusCode string
+}
+
+type RefundRequest struct {
+	RefundKey string
+	Amount    decimal.Decimal
+	Reason    string
+}
+
+type RefundResult struct {
+	RefundKey string
+	Amount    decimal.Decimal
+	Status    Status
+}
+
+type PaymentGateway interface {
+	Name() string
+	CreateCharge(req ChargeRequest) (*Charge, error)
+	GetStatus(orderID string) (*StatusResult, error)
+	Cancel(orderID string) (*StatusResult, error)
+	Refund(orderID string, req RefundRequest) (*RefundResult, error)
+	// Approve dan Deny memutuskan pembayaran yang di-challenge fraud detection
+	Approve(orderID string) (*StatusResult, error)
+	Deny(orderID string) (*StatusResult, error)
+	// ParseNotification memverifikasi payload webhook. Kalau error-nya
+	// ErrInvalidNotification, notifikasi tetap dikembalikan (sebisanya)
+	// supaya penolakannya bisa dicatat
+	ParseNotification(payload []byte) (*Notification, error)
+}
+
+// Simulator hanya diimplementasikan gateway palsu untuk mengubah status
+// pembayaran tanpa provider sungguhan
+type Simulator interface {
+	Simulate(orderID string, status Status) ([]byte, error)
+}
+
+// New memilih implementasi berdasarkan PAYMENT_DRIVER, default-nya midtrans
+func New(cfg *config.Config) PaymentGateway {
+	switch cfg.PaymentDriver {
+	case "fake":
+		return NewFakeGateway(cfg.AppBaseURL)
+	default:
+		return NewMidtransGateway(cfg.Midtrans)
+	}
+}
@@ -0,0 +1,139 @@
+package permission
+
+type Role string
+
+const (
+	RoleCustomer      Role = "customer"
+	RoleMerchantOwner Role = "merchant_owner"
+	RoleAdmin         Role = "admin"
+)
+
+// MerchantRole adalah role user di dalam satu merchant (membership)
+type MerchantRole string
+
+const (
+	MerchantRoleOwner      MerchantRole = "owner"
+	MerchantRoleManager    MerchantRole = "manager"
+	MerchantRoleCashier    MerchantRole = "cashier"
+	MerchantRoleStockClerk MerchantRole = "stock_clerk"
+)
+
+// All hanya dimiliki admin platform, lolos semua pengecekan permission
+const All = "*"
+
+const (
+	MerchantCreate           = "merchant:create"
+	MerchantDashboardRead    = "merchant:dashboard:read"
+	MerchantProductsRead     = "merchant:products:read"
+	MerchantProductsWrite    = "merchant:products:write"
+	MerchantInventoryWrite   = "merchant:inventory:write"
+	MerchantTransactionsRead = "merchant:transactions:read"
+	// MerchantTransactionsReview untuk approve/deny pembayaran yang di-challenge
+	MerchantTransactionsReview = "merchant:transactions:review"
+	MerchantTransactionsRefund = "merchant:transactions:refund"
+	// MerchantTransactionsFulfill memproses pesanan yang sudah dibayar.
+	// Menolak pesanan ikut me-refund, jadi diberikan ke role yang sama
+	MerchantTransactionsFulfill = "merchant:transactions:fulfill"
+	MerchantMembersRead         = "merchant:members:read"
+	MerchantMembersManage       = "merchant:members:manage"
+	MerchantAPIKeysManage       = "merchant:api_keys:manage"
+
+	TransactionsCreate = "transactions:create"
+	TransactionsRead   = "transactions:read"
+
+	// PaymentsReview melihat antrian review semua merchant, hanya admin (lewat All)
+	PaymentsReview = "payments:review"
+)
+
+var customerPermissions = []string{
+	MerchantCreate,
+	TransactionsCreate,
+	TransactionsRead,
+}
+
+var rolePermissions = map[Role][]string{
+	RoleCustomer:      customerPermissions,
+	RoleMerchantOwner: append([]string{MerchantDashboardRead}, customerPermissions...),
+	RoleAdmin:         {All},
+}
+
+// merchantRolePermissions adalah permission yang didapat member
+// terhadap merchant tempat dia terdaftar
+var merchantRolePermissions = map[MerchantRole][]string{
+	MerchantRoleOwner: {
+		MerchantDashboardRead,
+		MerchantProductsRead,
+		MerchantProductsWrite,
+		MerchantInventoryWrite,
+		MerchantTransactionsRead,
+		MerchantTransactionsReview,
+		MerchantTransactionsRefund,
+		MerchantTransactionsFulfill,
+		MerchantMembersRead,
+		MerchantMembersManage,
+		MerchantAPIKeysManage,
+	},
+	MerchantRoleManager: {
+		MerchantDashboardRead,
+		MerchantProductsRead,
+		MerchantProductsWrite,
+		MerchantInventoryWrite,
+		MerchantTransactionsRead,
+		MerchantTransactionsReview,
+		MerchantTransactionsRefund,
+		MerchantTransactionsFulfill,
+		MerchantMembersRead,
+	},
+	MerchantRoleCashier: {
+		MerchantDashboardRead,
+		MerchantProductsRead,
+		MerchantTransactionsRead,
+	},
+	MerchantRoleStockClerk: {
+		MerchantDashboardRead,
+		MerchantProductsRead,
+		MerchantInventoryWrite,
+	},
+}
+
+// apiKeyScopes adalah permission merchant yang boleh diberikan ke API key,
+// pengelolaan member dan API key sengaja tidak termasuk
+var apiKeyScopes = []string{
+	MerchantDashboardRead,
+	MerchantProductsRead,
+	MerchantProductsWrite,
+	MerchantInventoryWrite,
+	MerchantTransactionsRead,
+}
+
+func ForRole(role Role) []string {
+	permissions, ok := rolePermissions[role]
+	if !ok {
+		return rolePermissions[RoleCustomer]
+	}
+
+	return append([]string(nil), permissions...)
+}
+
+func ForMerchantRole(role MerchantRole) []string {
+	return append([]string(nil), merchantRolePermissions[role]...)
+}
+
+func IsValidMerchantRole(role MerchantRole) bool {
+	_, ok := merchantRolePermissions[role]
+	return ok
+}
+
+func IsValidAPIKeyScope(scope string) bool {
+	return Has(apiKeyScopes, scope)
+}
+
+func Has(granted []string, required string) bool {
+	for _, p := range granted {
+		if p == All || p == required {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package token
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	hostCookiePrefix   = "__Host-"
+	secureCookiePrefix = "__Secure-"
+)
+
+// CookiePolicy menentukan atribut semua cookie auth (access, refresh, csrf)
+// supaya binary yang sama bisa jalan di localhost (http) dan production
+type CookiePolicy struct {
+	Domain   string
+	Secure   bool
+	SameSite string
+
+	// HostPrefix menambahkan prefix __Host- (cookie dengan Path=/) atau
+	// __Secure- (refresh token yang Path-nya /api/auth)
+	HostPrefix bool
+
+	accessName  string
+	refreshName string
+	csrfName    string
+}
+
+func NewCookiePolicy(cfg config.CookieConfig) *CookiePolicy {
+	p := &CookiePolicy{
+		Domain:      cfg.Domain,
+		Secure:      cfg.Secure,
+		SameSite:    parseSameSite(cfg.SameSite),
+		HostPrefix:  cfg.HostPrefix,
+		accessName:  cfg.AccessTokenName,
+		refreshName: cfg.RefreshTokenName,
+		csrfName:    cfg.CSRFTokenName,
+	}
+
+	// browser menolak cookie __Host- yang punya Domain atau tidak Secure
+	if p.HostPrefix {
+		p.Domain = ""
+		p.Secure = true
+	}
+
+	// SameSite=None tanpa Secure juga ditolak browser
+	if p.SameSite == fiber.CookieSameSiteNoneMode && !p.Secure {
+		log.Println("cookie: SameSite=None requires Secure, enabling Secure")
+		p.Secure = true
+	}
+
+	return p
+}
+
+func (p *CookiePolicy) AccessTokenName() string {
+	return p.name(p.accessName, hostCookiePrefix)
+}
+
+func (p *CookiePolicy) RefreshTokenName() string {
+	return p.name(p.refreshName, secureCookiePrefix)
+}
+
+func (p *CookiePolicy) CSRFTokenName() string {
+	return p.name(p.csrfName, hostCookiePrefix)
+}
+
+// New membuat cookie dengan atribut dari policy. maxAge <= 0 berarti
+// cookie dihapus
+func (p *CookiePolicy) New(name, value, path string, maxAge time.Duration, httpOnly bool) *fiber.Cookie {
+	cookie := &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   p.Domain,
+		HTTPOnly: httpOnly,
+		Secure:   p.Secure,
+		SameSite: p.SameSite,
+	}
+
+	if maxAge > 0 {
+		cookie.MaxAge = int(maxAge.Seconds())
+		cookie.Expires = time.Now().Add(maxAge)
+	} else {
+		cookie.Value = ""
+		cookie.MaxAge = -1
+		cookie.Expires = time.Unix(0, 0)
+	}
+
+	return cookie
+}
+
+func (p *CookiePolicy) name(base string, prefix string) string {
+	if p.HostPrefix {
+		return prefix + base
+	}
+
+	return base
+}
+
+func parseSameSite(value string) string {
+	switch strings.ToLower(value) {
+	case "strict":
+		return fiber.CookieSameSiteStrictMode
+	case "none":
+		return fiber.CookieSameSiteNoneMode
+	default:
+		return fiber.CookieSameSiteLaxMode
+	}
+}
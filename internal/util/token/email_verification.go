@@ -0,0 +1,48 @@
+package token
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	EmailVerificationTTL = 24 * time.Hour
+
+	emailVerificationSubject = "email-verification"
+)
+
+// EmailVerificationClaims menyimpan email yang diverifikasi supaya link lama
+// otomatis tidak berlaku kalau user mengganti email
+type EmailVerificationClaims struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	jwt.RegisteredClaims
+}
+
+func (ks *KeySet) GenerateEmailVerificationToken(userID uuid.UUID, email string) (string, error) {
+	now := time.Now().UTC()
+	claims := EmailVerificationClaims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(EmailVerificationTTL)),
+			Issuer:    "go-fiber-api",
+			Subject:   emailVerificationSubject,
+		},
+	}
+
+	return ks.Sign(claims)
+}
+
+func (ks *KeySet) ParseEmailVerificationToken(tokenString string) (*EmailVerificationClaims, error) {
+	claims := &EmailVerificationClaims{}
+
+	if err := ks.Parse(tokenString, claims, emailVerificationSubject); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,278 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet berisi semua key yang boleh dipakai memverifikasi token dan satu
+// key aktif untuk menandatangani token baru.
+//
+// Rotasi key: taruh private key baru di JWT_KEYS_DIR (nama file = kid),
+// ganti JWT_ACTIVE_KID, lalu hapus key lama setelah token terakhir yang
+// ditandatangani key lama kedaluwarsa (lihat AccessTokenTTL).
+//
+// Contoh membuat key:
+//
+//	openssl genpkey -algorithm ed25519 -out keys/2026-10.pem
+//	openssl genpkey -algorithm rsa -pkeyopt rsa_keygen_bits:2048 -out keys/2026-10.pem
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+
+	// secret HS256 lama, tetap diterima untuk token tanpa kid
+	// supaya user tidak logout saat migrasi ke asymmetric key.
+	// Setelah legacyUntil lewat, secret lama tidak bisa dipakai lagi
+	// untuk membuat token yang valid
+	legacySecret []byte
+	legacyUntil  time.Time
+}
+
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+// Tokens berisi keyset dan cookie policy hasil konfigurasi. Dibuat sekali
+// di main lalu di-inject ke handler dan middleware yang membutuhkan
+type Tokens struct {
+	Keys    *KeySet
+	Cookies *CookiePolicy
+}
+
+func New(cfg *config.Config) (*Tokens, error) {
+	keys, err := LoadKeySet(cfg.JwtKeysDir, cfg.JwtActiveKID, cfg.JwtKey, cfg.JwtLegacyAcceptUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		Keys:    keys,
+		Cookies: NewCookiePolicy(cfg.Cookie),
+	}, nil
+}
+
+// LoadKeySet membaca semua file *.pem di dir. Kalau dir kosong, token
+// ditandatangani dengan HS256 memakai secret seperti sebelumnya. Kalau dir
+// diisi, token HS256 hanya diterima sampai legacyUntil. legacyUntil nol
+// berarti token HS256 langsung ditolak
+func LoadKeySet(dir string, activeKID string, secret string, legacyUntil time.Time) (*KeySet, error) {
+	ks := &KeySet{
+		keys: map[string]*signingKey{},
+	}
+
+	if secret != "" {
+		ks.legacySecret = []byte(secret)
+	}
+
+	if dir == "" {
+		if ks.legacySecret == nil {
+			return nil, errors.New("jwt: neither JWT_KEYS_DIR nor JWT_SECRET_KEY is configured")
+		}
+		return ks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		key, err := loadSigningKey(file, kid)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: load key %s: %w", file, err)
+		}
+
+		ks.keys[kid] = key
+	}
+
+	if len(ks.keys) == 0 {
+		return nil, fmt.Errorf("jwt: no *.pem keys found in %s", dir)
+	}
+
+	// tanpa JWT_ACTIVE_KID, pakai kid terakhir secara urutan nama file
+	if activeKID == "" {
+		activeKID = strings.TrimSuffix(filepath.Base(files[len(files)-1]), ".pem")
+	}
+
+	active, ok := ks.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("jwt: active key %q not found in %s", activeKID, dir)
+	}
+
+	ks.active = active
+	ks.legacyUntil = legacyUntil
+
+	return ks, nil
+}
+
+func loadSigningKey(file string, kid string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid pem")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, private: key}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodEdDSA, private: key}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.legacySecret)
+	}
+
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.kid
+
+	return token.SignedString(ks.active.private)
+}
+
+// Parse memverifikasi token dengan key sesuai kid dan memastikan subject-nya
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims, subject string) error {
+	parsedToken, err := jwt.ParseWithClaims(
+		tokenString,
+		claims,
+		ks.keyfunc,
+		jwt.WithValidMethods([]string{"RS256", "EdDSA", "HS256"}),
+		jwt.WithSubject(subject),
+	)
+	if err != nil {
+		return err
+	}
+
+	if !parsedToken.Valid {
+		return errors.New("invalid token")
+	}
+
+	return nil
+}
+
+func (ks *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok && ks.acceptsLegacy() {
+			return ks.legacySecret, nil
+		}
+		return nil, errors.New("token has no kid")
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+
+	// algoritma harus sesuai dengan jenis key, jangan percaya header alg
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.private.Public(), nil
+}
+
+// acceptsLegacy: tanpa keyset asymmetric HS256 satu-satunya key, jadi
+// selalu diterima. Dengan keyset, hanya sampai legacyUntil
+func (ks *KeySet) acceptsLegacy() bool {
+	if ks.legacySecret == nil {
+		return false
+	}
+
+	return ks.active == nil || time.Now().Before(ks.legacyUntil)
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS berisi public key semua key di keyset, termasuk yang sudah tidak
+// aktif, supaya service lain tetap bisa memverifikasi token lama
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	result := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(kids))}
+
+	for _, kid := range kids {
+		key := ks.keys[kid]
+
+		jwk := JSONWebKey{
+			Kid: kid,
+			Use: "sig",
+			Alg: key.method.Alg(),
+		}
+
+		switch public := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+
+		result.Keys = append(result.Keys, jwk)
+	}
+
+	return result
+}
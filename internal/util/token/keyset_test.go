@@ -0,0 +1,167 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// writeEd25519Key menulis private key baru ke dir/<kid>.pem
+func writeEd25519Key(t *testing.T, dir string, kid string) ed25519.PublicKey {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	return public
+}
+
+// legacyToken membuat access token HS256 tanpa kid seperti sebelum migrasi
+func legacyToken(t *testing.T, secret string) string {
+	t.Helper()
+
+	ks, err := LoadKeySet("", "", secret, time.Time{})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	signed, err := ks.SignAccessToken(uuid.New(), uuid.New(), "customer", nil)
+	if err != nil {
+		t.Fatalf("SignAccessToken: %v", err)
+	}
+
+	return signed
+}
+
+func TestLegacyTokenCutoff(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-10")
+
+	legacy := legacyToken(t, "secret")
+
+	tests := []struct {
+		name        string
+		secret      string
+		legacyUntil time.Time
+		wantErr     bool
+	}{
+		{name: "before cutoff", secret: "secret", legacyUntil: time.Now().Add(time.Hour)},
+		{name: "after cutoff", secret: "secret", legacyUntil: time.Now().Add(-time.Second), wantErr: true},
+		{name: "no cutoff", secret: "secret", wantErr: true},
+		{name: "no secret", legacyUntil: time.Now().Add(time.Hour), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := LoadKeySet(dir, "", tt.secret, tt.legacyUntil)
+			if err != nil {
+				t.Fatalf("LoadKeySet: %v", err)
+			}
+
+			_, err = ks.ParseToken(legacy)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ParseToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeySetSignsWithActiveKey(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-09")
+	writeEd25519Key(t, dir, "2026-10")
+
+	old, err := LoadKeySet(dir, "2026-09", "", time.Time{})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	oldToken, err := old.SignAccessToken(uuid.New(), uuid.New(), "customer", nil)
+	if err != nil {
+		t.Fatalf("SignAccessToken: %v", err)
+	}
+
+	// tanpa JWT_ACTIVE_KID dipakai kid terakhir, token dari key lama tetap valid
+	ks, err := LoadKeySet(dir, "", "", time.Time{})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	signed, err := ks.SignAccessToken(uuid.New(), uuid.New(), "customer", nil)
+	if err != nil {
+		t.Fatalf("SignAccessToken: %v", err)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(signed, &CustomClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+
+	if kid := parsed.Header["kid"]; kid != "2026-10" {
+		t.Fatalf("kid = %v, want 2026-10", kid)
+	}
+
+	for _, s := range []string{signed, oldToken} {
+		if _, err := ks.ParseToken(s); err != nil {
+			t.Fatalf("ParseToken: %v", err)
+		}
+	}
+
+	if _, err := LoadKeySet(dir, "2026-11", "", time.Time{}); err == nil {
+		t.Fatal("LoadKeySet with unknown active kid succeeded")
+	}
+}
+
+func TestJWKSPublishesAllPublicKeys(t *testing.T) {
+	dir := t.TempDir()
+	publics := map[string]ed25519.PublicKey{
+		"2026-09": writeEd25519Key(t, dir, "2026-09"),
+		"2026-10": writeEd25519Key(t, dir, "2026-10"),
+	}
+
+	ks, err := LoadKeySet(dir, "", "secret", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	jwks := ks.JWKS()
+	if len(jwks.Keys) != len(publics) {
+		t.Fatalf("jwks keys = %d, want %d", len(jwks.Keys), len(publics))
+	}
+
+	for _, jwk := range jwks.Keys {
+		public, ok := publics[jwk.Kid]
+		if !ok {
+			t.Fatalf("unexpected kid %q", jwk.Kid)
+		}
+
+		if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != "EdDSA" || jwk.Use != "sig" {
+			t.Fatalf("jwk %s = %+v, want Ed25519 signing key", jwk.Kid, jwk)
+		}
+
+		if jwk.X != base64.RawURLEncoding.EncodeToString(public) {
+			t.Fatalf("jwk %s does not match the public key", jwk.Kid)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix membedakan API key dari JWT di header Authorization
+const APIKeyPrefix = "mk_"
+
+// GenerateOpaqueToken mengembalikan token mentah (dikirim ke client)
+// dan hash-nya (disimpan di database)
+func GenerateOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	plain := base64.RawURLEncoding.EncodeToString(buf)
+
+	return plain, HashToken(plain), nil
+}
+
+func GenerateRefreshToken() (string, string, error) {
+	return GenerateOpaqueToken()
+}
+
+func GenerateAPIKey() (string, string, error) {
+	plain, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	plain = APIKeyPrefix + plain
+
+	return plain, HashToken(plain), nil
+}
+
+func IsAPIKey(value string) bool {
+	return strings.HasPrefix(value, APIKeyPrefix)
+}
+
+func HashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,19 +1,43 @@
 package token
 
-import "github.com/gofiber/fiber/v2"
-
-func SetAuthToken(c *fiber.Ctx, token string) {
-	c.Cookie(&fiber.Cookie{
-		Name:     "token",
-		Value:    token,
-		Path:     "/",
-		HTTPOnly: true,
-		// IMPORTANT (dev on localhost):
-		// - "Secure: true" + SameSite=None membuat cookie DITOLAK
-		//   oleh browser ketika berjalan di http://localhost.
-		// - Karena frontend (Next.js) dan backend sama-sama di "localhost"
-		//   hanya beda port, mereka tetap dianggap same-site, jadi Lax cukup.
-		Secure:   false,
-		SameSite: fiber.CookieSameSiteLaxMode,
-	})
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// refresh token hanya dikirim browser ke endpoint auth
+const refreshTokenPath = "/api/auth"
+
+// SetAuthToken menyimpan access token di cookie, umurnya sama dengan
+// AccessTokenTTL supaya cookie tidak tertinggal setelah JWT expired
+func (p *CookiePolicy) SetAuthToken(c *fiber.Ctx, token string) {
+	c.Cookie(p.New(p.AccessTokenName(), token, "/", AccessTokenTTL, true))
+}
+
+func (p *CookiePolicy) SetRefreshToken(c *fiber.Ctx, token string, expiresAt time.Time) {
+	c.Cookie(p.New(p.RefreshTokenName(), token, refreshTokenPath, time.Until(expiresAt), true))
+}
+
+// SetCSRFToken sengaja tidak HttpOnly supaya frontend bisa membaca nilainya
+// dan mengirim ulang lewat header X-CSRF-Token (double-submit cookie)
+func (p *CookiePolicy) SetCSRFToken(c *fiber.Ctx, token string) {
+	c.Cookie(p.New(p.CSRFTokenName(), token, "/", RefreshTokenTTL, false))
+}
+
+func (p *CookiePolicy) ClearAuthTokens(c *fiber.Ctx) {
+	c.Cookie(p.New(p.AccessTokenName(), "", "/", 0, true))
+	c.Cookie(p.New(p.RefreshTokenName(), "", refreshTokenPath, 0, true))
+}
+
+func (p *CookiePolicy) AccessTokenFromCookie(c *fiber.Ctx) string {
+	return c.Cookies(p.AccessTokenName())
+}
+
+func (p *CookiePolicy) RefreshTokenFromCookie(c *fiber.Ctx) string {
+	return c.Cookies(p.RefreshTokenName())
+}
+
+func (p *CookiePolicy) CSRFTokenFromCookie(c *fiber.Ctx) string {
+	return c.Cookies(p.CSRFTokenName())
 }
@@ -2,72 +2,60 @@ package token
 
 import (
 	"errors"
-	"go-fiber-api/internal/config"
 	"time"
 
-	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
 type CustomClaims struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	SessionID   uuid.UUID `json:"sid"`
+	Role        string    `json:"role"`
+	Permissions []string  `json:"permissions"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(c *fiber.Ctx, userID uuid.UUID) (string, error) {
-	cfg := config.Get()
-
+// SignAccessToken hanya menandatangani JWT, pemanggil yang memutuskan
+// token dikirim lewat cookie atau body response
+func (ks *KeySet) SignAccessToken(
+	userID uuid.UUID,
+	sessionID uuid.UUID,
+	role string,
+	permissions []string,
+) (string, error) {
 	now := time.Now().UTC()
 	claims := CustomClaims{
-		UserID: userID,
+		UserID:      userID,
+		SessionID:   sessionID,
+		Role:        role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
 			Issuer:    "go-fiber-api",
 			Subject:   "auth-token",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString([]byte(cfg.JwtKey))
-	if err != nil {
-		return "", err
-	}
-
-	SetAuthToken(c, tokenString)
-
-	return tokenString, nil
+	return ks.Sign(claims)
 }
 
-func ParseToken(tokenString string) (*CustomClaims, error) {
-	cfg := config.Get()
-
+func (ks *KeySet) ParseToken(tokenString string) (*CustomClaims, error) {
 	claims := &CustomClaims{}
 
-	parsedToken, err := jwt.ParseWithClaims(
-		tokenString,
-		claims,
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(cfg.JwtKey), nil
-		},
-	)
-	if err != nil {
+	if err := ks.Parse(tokenString, claims, "auth-token"); err != nil {
 		return nil, err
 	}
 
-	if !parsedToken.Valid {
-		return nil, errors.New("invalid token")
-	}
-
-	customClaims, ok := parsedToken.Claims.(*CustomClaims)
-	if !ok {
-		return nil, errors.New("could not parse claims")
+	if claims.SessionID == uuid.Nil {
+		return nil, errors.New("token is not bound to a session")
 	}
 
-	return customClaims, nil
+	return claims, nil
 }
@@ -0,0 +1,56 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// vektor uji RFC 6238 (SHA1), diambil 6 digit terakhir
+func TestValidateRFC6238Vectors(t *testing.T) {
+	secret := encoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tt := range tests {
+		at := time.Unix(tt.unix, 0)
+
+		step, ok := Validate(secret, tt.code, at)
+		if !ok {
+			t.Fatalf("Validate(%s) at %d rejected", tt.code, tt.unix)
+		}
+
+		if want := tt.unix / int64(Period.Seconds()); step != want {
+			t.Fatalf("step at %d = %d, want %d", tt.unix, step, want)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	key, _ := encoding.DecodeString(secret)
+	now := time.Unix(1_800_000_000, 0)
+	current := now.Unix() / int64(Period.Seconds())
+
+	for offset, want := range map[int64]bool{-2: false, -1: true, 0: true, 1: true, 2: false} {
+		if _, ok := Validate(secret, generate(key, current+offset), now); ok != want {
+			t.Errorf("code at step offset %d accepted = %v, want %v", offset, ok, want)
+		}
+	}
+
+	if _, ok := Validate(secret, "12345", now); ok {
+		t.Error("short code accepted")
+	}
+}
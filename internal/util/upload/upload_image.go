@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"go-fiber-api/internal/config"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -20,14 +19,28 @@ type SupabaseUploadResult struct {
 	ObjectPath string
 }
 
-func UploadToSupabaseStorage(
+type Storage interface {
+	UploadToSupabaseStorage(ctx context.Context, fileHeader *multipart.FileHeader, prefix string) (*SupabaseUploadResult, error)
+}
+
+type supabaseStorage struct {
+	baseURL    string
+	serviceKey string
+}
+
+func NewSupabaseStorage(baseURL string, serviceKey string) Storage {
+	return &supabaseStorage{
+		baseURL:    baseURL,
+		serviceKey: serviceKey,
+	}
+}
+
+func (s *supabaseStorage) UploadToSupabaseStorage(
 	ctx context.Context,
 	fileHeader *multipart.FileHeader,
 	prefix string,
 ) (*SupabaseUploadResult, error) {
 
-	configuration := config.Get()
-
 	uploadedFile, err := fileHeader.Open()
 	if err != nil {
 		return nil, fmt.Errorf("open uploaded file failed: %w", err)
@@ -54,7 +67,7 @@ func UploadToSupabaseStorage(
 	// Build upload endpoint
 	uploadURL := fmt.Sprintf(
 		"%s/storage/v1/object/%s/%s",
-		configuration.SupabaseURL,
+		s.baseURL,
 		url.PathEscape("merchant"),
 		url.PathEscape(objectPath),
 	)
@@ -71,7 +84,7 @@ func UploadToSupabaseStorage(
 
 	request.Header.Set(
 		"Authorization",
-		"Bearer "+configuration.SupabaseServiceKey,
+		"Bearer "+s.serviceKey,
 	)
 	request.Header.Set("Content-Type", fileHeader.Header.Get("Content-Type"))
 	request.Header.Set("x-upsert", "true")
@@ -94,7 +107,7 @@ func UploadToSupabaseStorage(
 
 	publicURL := fmt.Sprintf(
 		"%s/storage/v1/object/public/%s/%s",
-		configuration.SupabaseURL,
+		s.baseURL,
 		"merchant",
 		objectPath,
 	)
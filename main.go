@@ -1,35 +1,57 @@
 package main
 
 import (
+	"context"
+	"log"
+
 	"go-fiber-api/internal/api"
 	"go-fiber-api/internal/config"
 	"go-fiber-api/internal/connection"
+	"go-fiber-api/internal/util/payment"
+	"go-fiber-api/internal/util/token"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tokens, err := token.New(cfg)
+	if err != nil {
+		log.Fatal("failed to load jwt keys: ", err)
+	}
+
 	app := fiber.New()
-	db, err := connection.ConnectDB()
-	err = config.InitSupabase()
+	db, err := connection.ConnectDB(cfg)
 	if err != nil {
-		panic("failed to initialize Supabase or Database: " + err.Error())
+		log.Fatal("failed to initialize Database: ", err)
 	}
 
-	config.InitMidtrans()
+	paymentGateway := payment.New(cfg)
 
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "http://localhost:3000",
+		AllowOrigins:     cfg.AppBaseURL,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders:     "Content-Type, Authorization",
+		AllowHeaders:     "Content-Type, Authorization, X-CSRF-Token",
 		AllowCredentials: true,
 	}))
 
-	api.RegisterAuthRoutes(app, db)
-	api.RegisterMerchantRoutes(app, db)
-	api.RegisterProductRoutes(app, db)
-	api.RegisterFollowRoutes(app, db)
-	api.RegisterTransactionRoutes(app, db)
-	app.Listen(":8080")
+	api.RegisterCSRFProtection(app, tokens, paymentGateway)
+	api.RegisterAuthRoutes(app, db, cfg, tokens)
+	api.RegisterMerchantRoutes(app, db, cfg, tokens)
+	api.RegisterProductRoutes(app, db, cfg, tokens)
+	api.RegisterFollowRoutes(app, db, tokens)
+	api.RegisterTransactionRoutes(app, db, cfg, tokens, paymentGateway)
+	api.RegisterPaymentRoutes(app, cfg, paymentGateway)
+	api.RegisterStockMovementRoutes(app, db, tokens)
+	api.RegisterPrivacyRoutes(app, db, cfg, tokens)
+	api.RegisterWellKnownRoutes(app, tokens.Keys)
+
+	api.StartTransactionExpiry(context.Background(), db, cfg, paymentGateway)
+
+	log.Fatal(app.Listen(":" + cfg.Port))
 }